@@ -7,8 +7,11 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/eleonorayaya/utena/internal/journal"
 	"github.com/eleonorayaya/utena/internal/session"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/eleonorayaya/utena/internal/zellij"
@@ -17,15 +20,35 @@ import (
 	"github.com/go-chi/render"
 )
 
+const busDrainTimeout = 5 * time.Second
+
 func StartDaemon() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	bus := eventbus.NewEventBus()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	bus := newEventBus(cfg)
+	eventStream := NewEventStream(bus)
+	journalModule := journal.NewJournalModule(cfg, bus)
 
-	workspaceModule := workspace.NewWorkspaceModule()
-	sessionModule := session.NewSessionModule(workspaceModule, bus)
-	zellijModule := zellij.NewZellijModule(sessionModule, bus)
+	workspaceModule := workspace.NewWorkspaceModule(cfg, bus)
+	sessionModule := session.NewSessionModule(cfg, workspaceModule, bus)
+	deadLetters := eventbus.NewDeadLetterStore(cfg.DeadLetterLimit)
+	zellijModule := zellij.NewZellijModule(cfg, sessionModule, bus, deadLetters)
+
+	// Subscribe before the modules start so their startup events are streamed
+	// and journaled
+	if err := eventStream.OnAppStart(ctx); err != nil {
+		log.Fatalf("Failed to initialize event stream: %v", err)
+	}
+
+	if err := journalModule.OnAppStart(ctx); err != nil {
+		log.Fatalf("Failed to initialize journal module: %v", err)
+	}
 
 	if err := workspaceModule.OnAppStart(ctx); err != nil {
 		log.Fatalf("Failed to initialize workspace module: %v", err)
@@ -39,10 +62,25 @@ func StartDaemon() {
 		log.Fatalf("Failed to initialize zellij module: %v", err)
 	}
 
-	go serveAPI(ctx, workspaceModule, sessionModule, zellijModule)
+	go serveAPI(ctx, eventStream, journalModule, NewDeadLetterController(deadLetters), workspaceModule, sessionModule, zellijModule)
 
 	<-ctx.Done()
 
+	if err := eventStream.OnAppEnd(ctx); err != nil {
+		log.Printf("Error closing event stream: %v", err)
+	}
+
+	// Let queued events finish before the modules they touch shut down
+	drainCtx, cancel := context.WithTimeout(context.Background(), busDrainTimeout)
+	defer cancel()
+	if err := bus.Close(drainCtx); err != nil {
+		log.Printf("Error draining event bus: %v", err)
+	}
+
+	if err := journalModule.OnAppEnd(ctx); err != nil {
+		log.Printf("Error cleaning up journal module: %v", err)
+	}
+
 	if err := zellijModule.OnAppEnd(ctx); err != nil {
 		log.Printf("Error cleaning up zellij module: %v", err)
 	}
@@ -56,7 +94,20 @@ func StartDaemon() {
 	}
 }
 
-func serveAPI(ctx context.Context, workspaceModule *workspace.WorkspaceModule, sessionModule *session.SessionModule, zellijModule *zellij.ZellijModule) {
+func newEventBus(cfg *config.Config) eventbus.EventBus {
+	if cfg.EventDelivery == config.EventDeliverySync {
+		return eventbus.NewEventBus()
+	}
+
+	return eventbus.NewAsyncEventBus(
+		eventbus.WithQueueSize(cfg.EventQueueSize),
+		eventbus.WithErrorHandler(func(event eventbus.Event, err error) {
+			log.Printf("Event %s failed: %v", event.Type, err)
+		}),
+	)
+}
+
+func serveAPI(ctx context.Context, eventStream *EventStream, journalModule *journal.JournalModule, deadLetters *DeadLetterController, workspaceModule *workspace.WorkspaceModule, sessionModule *session.SessionModule, zellijModule *zellij.ZellijModule) {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
@@ -68,6 +119,11 @@ func serveAPI(ctx context.Context, workspaceModule *workspace.WorkspaceModule, s
 	r.Mount("/workspaces", workspaceModule.Routes())
 	r.Mount("/sessions", sessionModule.Routes())
 	r.Mount("/zellij", zellijModule.Routes())
+	r.Route("/events", func(r chi.Router) {
+		r.Get("/", eventStream.ServeHTTP)
+		r.Mount("/dead-letters", deadLetters.Routes())
+		r.Mount("/", journalModule.Routes())
+	})
 
 	log.Println("Starting daemon on :3333")
 	http.ListenAndServe(":3333", r)
@@ -4,12 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/eleonorayaya/utena/internal/journal"
 	"github.com/eleonorayaya/utena/internal/session"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/eleonorayaya/utena/internal/zellij"
@@ -23,14 +28,59 @@ import (
 func setupTestRouter(t *testing.T) chi.Router {
 	t.Helper()
 
+	router, _ := setupTestRouterWithBus(t)
+	return router
+}
+
+// setupTestRouterWithBus is setupTestRouter that also returns the event bus.
+// Commands for the plugin are recorded unless opts pick another sender.
+func setupTestRouterWithBus(t *testing.T, opts ...zellij.ZellijOption) (chi.Router, eventbus.EventBus) {
+	t.Helper()
+
+	return setupTestRouterWithDelivery(t, config.EventDeliverySync, opts...)
+}
+
+// setupTestRouterWithDelivery builds the router on the bus event_delivery
+// picks, as the daemon does. An async bus is closed when the test ends.
+func setupTestRouterWithDelivery(t *testing.T, delivery string, opts ...zellij.ZellijOption) (chi.Router, eventbus.EventBus) {
+	t.Helper()
+
 	ctx := context.Background()
 
-	bus := eventbus.NewEventBus()
+	// Discover workspaces from a temporary root
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "utena", ".git"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "example-project"), 0o755))
+
+	cfg := config.Default()
+	cfg.WorkspaceRoots = []string{root}
+	cfg.StorageBackend = config.StorageMemory
+	cfg.JournalPath = filepath.Join(t.TempDir(), "events.jsonl")
+	cfg.ReconcileIntervalMs = 0
+	cfg.EventRetryBackoffMs = 1
+	cfg.EventRetryMaxBackoffMs = 1
+	cfg.EventDelivery = delivery
+
+	bus := newEventBus(cfg)
+
+	eventStream := NewEventStream(bus, WithHeartbeatInterval(20*time.Millisecond))
+	require.NoError(t, eventStream.OnAppStart(ctx))
+	t.Cleanup(func() {
+		eventStream.OnAppEnd(ctx)
+	})
+
+	journalModule := journal.NewJournalModule(cfg, bus)
+	require.NoError(t, journalModule.OnAppStart(ctx))
+	t.Cleanup(func() {
+		journalModule.OnAppEnd(ctx)
+	})
 
 	// Initialize modules
-	workspaceModule := workspace.NewWorkspaceModule()
-	sessionModule := session.NewSessionModule(workspaceModule, bus)
-	zellijModule := zellij.NewZellijModule(sessionModule, bus)
+	workspaceModule := workspace.NewWorkspaceModule(cfg, bus)
+	sessionModule := session.NewSessionModule(cfg, workspaceModule, bus)
+	deadLetters := eventbus.NewDeadLetterStore(cfg.DeadLetterLimit)
+	opts = append([]zellij.ZellijOption{zellij.WithCommandSender(zellij.NewRecordingSender())}, opts...)
+	zellijModule := zellij.NewZellijModule(cfg, sessionModule, bus, deadLetters, opts...)
 
 	// Call OnAppStart for all modules
 	err := workspaceModule.OnAppStart(ctx)
@@ -53,8 +103,41 @@ func setupTestRouter(t *testing.T) chi.Router {
 	r.Mount("/workspaces", workspaceModule.Routes())
 	r.Mount("/sessions", sessionModule.Routes())
 	r.Mount("/zellij", zellijModule.Routes())
+	r.Route("/events", func(r chi.Router) {
+		r.Get("/", eventStream.ServeHTTP)
+		r.Mount("/dead-letters", NewDeadLetterController(deadLetters).Routes())
+		r.Mount("/", journalModule.Routes())
+	})
+
+	// Registered last so it runs first: the daemon closes the bus before
+	// the modules shut down
+	t.Cleanup(func() {
+		bus.Close(ctx)
+	})
+
+	return r, bus
+}
 
-	return r
+// workspaceIDByName looks up a discovered workspace ID through the API
+func workspaceIDByName(t *testing.T, router chi.Router, name string) string {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/workspaces", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response workspace.WorkspaceListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	for _, ws := range response.Workspaces {
+		if ws.Name == name {
+			return ws.ID
+		}
+	}
+
+	t.Fatalf("workspace %q not found", name)
+	return ""
 }
 
 func TestDaemon_ListWorkspaces(t *testing.T) {
@@ -72,19 +155,18 @@ func TestDaemon_ListWorkspaces(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, response.Workspaces, 2)
 
-	// Verify hard-coded workspaces
-	ids := make(map[string]bool)
-	for _, ws := range response.Workspaces {
-		ids[ws.ID] = true
-	}
-	require.True(t, ids["ws-1"])
-	require.True(t, ids["ws-2"])
+	// Verify discovered workspaces, sorted by name
+	require.Equal(t, "example-project", response.Workspaces[0].Name)
+	require.False(t, response.Workspaces[0].IsGitRepo)
+	require.Equal(t, "utena", response.Workspaces[1].Name)
+	require.True(t, response.Workspaces[1].IsGitRepo)
 }
 
 func TestDaemon_GetWorkspaceByID(t *testing.T) {
 	router := setupTestRouter(t)
+	wsID := workspaceIDByName(t, router, "utena")
 
-	req := httptest.NewRequest("GET", "/workspaces/ws-1", nil)
+	req := httptest.NewRequest("GET", "/workspaces/"+wsID, nil)
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
@@ -94,19 +176,19 @@ func TestDaemon_GetWorkspaceByID(t *testing.T) {
 	var response workspace.WorkspaceResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
-	require.Equal(t, "ws-1", response.ID)
+	require.Equal(t, wsID, response.ID)
 	require.Equal(t, "utena", response.Name)
 }
 
 func TestDaemon_CreateAndGetSession(t *testing.T) {
 	router := setupTestRouter(t)
+	wsID := workspaceIDByName(t, router, "utena")
 
 	// Create session
 	sess := &session.Session{
 		ID:          "test-session-1",
-		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		WorkspaceID: wsID,
+		State:       session.StateAttached,
 		LastUsedAt:  time.Now(),
 	}
 	body, err := json.Marshal(sess)
@@ -132,23 +214,28 @@ func TestDaemon_CreateAndGetSession(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
 	require.Equal(t, "test-session-1", response.ID)
-	require.Equal(t, "ws-1", response.WorkspaceID)
-	require.True(t, response.IsAttached)
+	require.Equal(t, wsID, response.WorkspaceID)
+
+	// Zellij was asked to create the session but hasn't reported it yet
+	require.Equal(t, session.StateStarting, response.State)
+	require.False(t, response.IsAttached())
 }
 
 func TestDaemon_ListSessions(t *testing.T) {
 	router := setupTestRouter(t)
+	utenaID := workspaceIDByName(t, router, "utena")
+	exampleID := workspaceIDByName(t, router, "example-project")
 
 	// Create multiple sessions
 	sessions := []*session.Session{
 		{
 			ID:          "session-1",
-			WorkspaceID: "ws-1",
+			WorkspaceID: utenaID,
 			LastUsedAt:  time.Now().Add(-1 * time.Hour),
 		},
 		{
 			ID:          "session-2",
-			WorkspaceID: "ws-2",
+			WorkspaceID: exampleID,
 			LastUsedAt:  time.Now(),
 		},
 	}
@@ -191,6 +278,7 @@ func TestDaemon_ZellijSessionUpdate(t *testing.T) {
 			{
 				Name:             "main-session",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 			{
 				Name:             "background-session",
@@ -229,15 +317,15 @@ func TestDaemon_ZellijSessionUpdate(t *testing.T) {
 
 	mainSession := findSessionByID(sessionsResponse.Sessions, "main-session")
 	require.NotNil(t, mainSession)
-	require.True(t, mainSession.IsAttached)
-	require.True(t, mainSession.IsActive)
-	require.False(t, mainSession.IsDead)
+	require.True(t, mainSession.IsAttached())
+	require.True(t, mainSession.IsActive())
+	require.False(t, mainSession.IsDead())
 
 	bgSession := findSessionByID(sessionsResponse.Sessions, "background-session")
 	require.NotNil(t, bgSession)
-	require.False(t, bgSession.IsAttached)
-	require.True(t, bgSession.IsActive)
-	require.False(t, bgSession.IsDead)
+	require.False(t, bgSession.IsAttached())
+	require.True(t, bgSession.IsActive())
+	require.False(t, bgSession.IsDead())
 }
 
 func findSessionByID(sessions []session.Session, id string) *session.Session {
@@ -251,19 +339,18 @@ func findSessionByID(sessions []session.Session, id string) *session.Session {
 
 func TestDaemon_ZellijSessionUpdate_MarkDeadSessions(t *testing.T) {
 	router := setupTestRouter(t)
+	wsID := workspaceIDByName(t, router, "utena")
 
 	sess1 := &session.Session{
 		ID:          "old-session-1",
-		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		WorkspaceID: wsID,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sess2 := &session.Session{
 		ID:          "old-session-2",
-		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		WorkspaceID: wsID,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 
@@ -283,11 +370,27 @@ func TestDaemon_ZellijSessionUpdate_MarkDeadSessions(t *testing.T) {
 	router.ServeHTTP(w2, req2)
 	require.Equal(t, http.StatusCreated, w2.Code)
 
+	// Zellij reports both sessions running
+	startedReq := &zellij.UpdateSessionsRequest{
+		Sessions: []zellij.SessionUpdate{
+			{Name: "old-session-1"},
+			{Name: "old-session-2"},
+		},
+	}
+	startedBody, err := json.Marshal(startedReq)
+	require.NoError(t, err)
+	req0 := httptest.NewRequest("PUT", "/zellij/sessions", bytes.NewReader(startedBody))
+	req0.Header.Set("Content-Type", "application/json")
+	w0 := httptest.NewRecorder()
+	router.ServeHTTP(w0, req0)
+	require.Equal(t, http.StatusOK, w0.Code)
+
 	updateReq := &zellij.UpdateSessionsRequest{
 		Sessions: []zellij.SessionUpdate{
 			{
 				Name:             "old-session-1",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 			{
 				Name:             "new-session",
@@ -321,15 +424,464 @@ func TestDaemon_ZellijSessionUpdate_MarkDeadSessions(t *testing.T) {
 
 	oldSession1 := findSessionByID(sessionsResponse.Sessions, "old-session-1")
 	require.NotNil(t, oldSession1)
-	require.True(t, oldSession1.IsAttached)
-	require.False(t, oldSession1.IsDead)
+	require.True(t, oldSession1.IsAttached())
+	require.False(t, oldSession1.IsDead())
 
 	oldSession2 := findSessionByID(sessionsResponse.Sessions, "old-session-2")
 	require.NotNil(t, oldSession2)
-	require.True(t, oldSession2.IsDead)
+	require.True(t, oldSession2.IsDead())
+	require.False(t, oldSession2.IsActive())
 
 	newSession := findSessionByID(sessionsResponse.Sessions, "new-session")
 	require.NotNil(t, newSession)
-	require.False(t, newSession.IsAttached)
-	require.False(t, newSession.IsDead)
+	require.False(t, newSession.IsAttached())
+	require.False(t, newSession.IsDead())
+}
+
+func TestDaemon_ActivateSession_NotFound(t *testing.T) {
+	router := setupTestRouter(t)
+
+	req := httptest.NewRequest("PUT", "/sessions/nonexistent/activate", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDaemon_ZellijSessionUpdate_GroupsSessionsByWorkspace(t *testing.T) {
+	router := setupTestRouter(t)
+	wsID := workspaceIDByName(t, router, "utena")
+
+	req := httptest.NewRequest("GET", "/workspaces/"+wsID, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var ws workspace.WorkspaceResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ws))
+
+	updateReq := &zellij.UpdateSessionsRequest{
+		Sessions: []zellij.SessionUpdate{
+			{
+				Name:             "utena-main",
+				IsCurrentSession: true,
+				ConnectedClients: 1,
+				Cwd:              filepath.Join(ws.Path, "internal"),
+			},
+			{
+				Name:             "scratch",
+				IsCurrentSession: false,
+			},
+		},
+	}
+
+	body, err := json.Marshal(updateReq)
+	require.NoError(t, err)
+
+	req = httptest.NewRequest("PUT", "/zellij/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/sessions/workspace/"+wsID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response session.SessionListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Sessions, 1)
+	require.Equal(t, "utena-main", response.Sessions[0].ID)
+
+	req = httptest.NewRequest("GET", "/sessions/workspace/"+workspace.UnassignedWorkspaceID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Sessions, 1)
+	require.Equal(t, "scratch", response.Sessions[0].ID)
+}
+
+func TestDaemon_EventHistory(t *testing.T) {
+	router := setupTestRouter(t)
+	wsID := workspaceIDByName(t, router, "utena")
+
+	body, err := json.Marshal(&session.Session{ID: "test-session-1", WorkspaceID: wsID})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	req = httptest.NewRequest("GET", "/events/history?type=session.&session_id=test-session-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response journal.HistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Entries)
+	for _, entry := range response.Entries {
+		require.Equal(t, "test-session-1", entry.SessionID)
+		require.Equal(t, "req-42", entry.CorrelationID)
+	}
+
+	req = httptest.NewRequest("GET", "/events/history?since=yesterday", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDaemon_DeadLetters_Redrive(t *testing.T) {
+	// zellij pipe keeps failing, so the create command is dead-lettered
+	sender := zellij.NewRecordingSender()
+	sender.FailWith(errors.New("zellij pipe failed"))
+
+	router, _ := setupTestRouterWithBus(t, zellij.WithCommandSender(sender))
+	wsID := workspaceIDByName(t, router, "utena")
+
+	body, err := json.Marshal(&session.Session{ID: "test-session-1", WorkspaceID: wsID})
+	require.NoError(t, err)
+
+	// The session is still requested; the failure shows on the command
+	req := httptest.NewRequest("POST", "/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created session.CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.Equal(t, session.StateRequested, created.State)
+	require.NotEmpty(t, created.CommandID)
+
+	req = httptest.NewRequest("GET", "/zellij/commands/"+created.CommandID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status zellij.CommandStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.CommandFailed, status.State)
+
+	req = httptest.NewRequest("GET", "/events/dead-letters", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var letters DeadLetterListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &letters))
+	require.Len(t, letters.DeadLetters, 1)
+	require.Equal(t, "zellij.create_session", letters.DeadLetters[0].Subscriber)
+	require.Equal(t, eventbus.SessionCreateRequested, letters.DeadLetters[0].Type)
+	require.Contains(t, letters.DeadLetters[0].Error, "zellij pipe failed")
+
+	// Once zellij is back, re-driving creates the session
+	sender.FailWith(nil)
+
+	req = httptest.NewRequest("POST", "/events/dead-letters", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var redrive RedriveResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &redrive))
+	require.Equal(t, []string{letters.DeadLetters[0].ID}, redrive.Redriven)
+	require.Empty(t, redrive.Failed)
+
+	req = httptest.NewRequest("GET", "/events/dead-letters", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &letters))
+	require.Empty(t, letters.DeadLetters)
+
+	req = httptest.NewRequest("GET", "/sessions/test-session-1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response session.SessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, session.StateStarting, response.State)
+}
+
+func TestDaemon_ActivateSession_CommandResult(t *testing.T) {
+	router := setupTestRouter(t)
+
+	updateReq := zellij.UpdateSessionsRequest{
+		Sessions: []zellij.SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}},
+	}
+	body, err := json.Marshal(updateReq)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("PUT", "/zellij/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("PUT", "/sessions/utena-main/activate", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var activation session.ActivateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &activation))
+	require.NotEmpty(t, activation.CommandID)
+
+	// The pipe accepted the command, but the plugin hasn't reported back
+	req = httptest.NewRequest("GET", "/zellij/commands/"+activation.CommandID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status zellij.CommandStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.CommandPending, status.State)
+	require.Equal(t, "switch_session", status.Command)
+
+	req = httptest.NewRequest("POST", "/zellij/commands/"+activation.CommandID+"/result", bytes.NewReader([]byte(`{"success": false, "error": "no such session"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/zellij/commands/"+activation.CommandID+"?wait=1s", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.CommandFailed, status.State)
+	require.Equal(t, "no such session", status.Error)
+
+	// Reporting twice conflicts
+	req = httptest.NewRequest("POST", "/zellij/commands/"+activation.CommandID+"/result", bytes.NewReader([]byte(`{"success": true}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestDaemon_ZellijHeartbeatAndStatus(t *testing.T) {
+	router := setupTestRouter(t)
+
+	req := httptest.NewRequest("GET", "/zellij/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status zellij.StatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.Disconnected, status.Status)
+	require.Nil(t, status.LastSeenAt)
+
+	req = httptest.NewRequest("POST", "/zellij/heartbeat", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest("POST", "/zellij/heartbeat", bytes.NewReader([]byte(`{"id": "4821-3", "version": "0.1.0", "host_session": "utena-main"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("GET", "/zellij/status", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.Connected, status.Status)
+	require.NotNil(t, status.LastSeenAt)
+	require.Equal(t, 1, status.Instances)
+
+	req = httptest.NewRequest("GET", "/zellij/instances", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var instances zellij.InstanceListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &instances))
+	require.Len(t, instances.Instances, 1)
+	require.Equal(t, "4821-3", instances.Instances[0].ID)
+}
+
+func TestDaemon_ZellijRegister(t *testing.T) {
+	router := setupTestRouter(t)
+
+	req := httptest.NewRequest("POST", "/zellij/register", bytes.NewReader([]byte(`{"id": "4821-3"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest("POST", "/zellij/register", bytes.NewReader([]byte(`{"id": "4821-3", "protocol_version": -1}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	body := `{"id": "4821-3", "version": "0.1.0", "host_session": "utena-main", "protocol_version": 1, "commands": ["open_picker", "switch_session"]}`
+	req = httptest.NewRequest("POST", "/zellij/register", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var registered zellij.RegisterResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &registered))
+	require.Equal(t, 1, registered.ProtocolVersion)
+	require.Equal(t, zellij.MinProtocolVersion, registered.MinProtocolVersion)
+	require.Equal(t, zellij.ProtocolVersion, registered.MaxProtocolVersion)
+	require.Equal(t, []string{"open_picker", "switch_session"}, registered.Instance.Commands)
+}
+
+// waitForCommand fetches a command's status, waiting up to a second for the
+// plugin to report it
+func waitForCommand(t *testing.T, router chi.Router, id string) zellij.CommandStatusResponse {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/zellij/commands/"+id+"?wait=1s", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status zellij.CommandStatusResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	return status
+}
+
+func reportCommandResult(t *testing.T, router chi.Router, id string, result string) {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/zellij/commands/"+id+"/result", bytes.NewReader([]byte(result)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func getSessionState(t *testing.T, router chi.Router, id string) session.State {
+	t.Helper()
+
+	req := httptest.NewRequest("GET", "/sessions/"+id, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response session.SessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.State
+}
+
+func TestDaemon_AsyncBus_CreateAndActivateSession(t *testing.T) {
+	sender := zellij.NewRecordingSender()
+	router, _ := setupTestRouterWithDelivery(t, config.EventDeliveryAsync, zellij.WithCommandSender(sender))
+	wsID := workspaceIDByName(t, router, "utena")
+
+	body, err := json.Marshal(&session.Session{ID: "test-session-1", WorkspaceID: wsID})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created session.CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEmpty(t, created.CommandID)
+
+	// The create is handled after the response; the session moves on once
+	// the command is out
+	require.Eventually(t, func() bool {
+		return getSessionState(t, router, "test-session-1") == session.StateStarting
+	}, time.Second, 5*time.Millisecond)
+
+	reportCommandResult(t, router, created.CommandID, `{"success": true}`)
+	require.Equal(t, zellij.CommandSucceeded, waitForCommand(t, router, created.CommandID).State)
+
+	req = httptest.NewRequest("PUT", "/sessions/test-session-1/activate", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var activation session.ActivateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &activation))
+	require.NotEmpty(t, activation.CommandID)
+
+	// The plugin can only report on a command the daemon already sent
+	require.Eventually(t, func() bool {
+		return len(sender.Commands()) == 2
+	}, time.Second, 5*time.Millisecond)
+	reportCommandResult(t, router, activation.CommandID, `{"success": false, "error": "no such session"}`)
+
+	status := waitForCommand(t, router, activation.CommandID)
+	require.Equal(t, zellij.CommandFailed, status.State)
+	require.Equal(t, "no such session", status.Error)
+}
+
+func TestDaemon_AsyncBus_SendFailureIsReportedOnTheCommand(t *testing.T) {
+	sender := zellij.NewRecordingSender()
+	sender.FailWith(errors.New("zellij pipe failed"))
+
+	router, _ := setupTestRouterWithDelivery(t, config.EventDeliveryAsync, zellij.WithCommandSender(sender))
+	wsID := workspaceIDByName(t, router, "utena")
+
+	body, err := json.Marshal(&session.Session{ID: "test-session-1", WorkspaceID: wsID})
+	require.NoError(t, err)
+
+	// The publisher can't see the failure, so the request succeeds
+	req := httptest.NewRequest("POST", "/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created session.CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// The command fails only once the retries are used up
+	status := waitForCommand(t, router, created.CommandID)
+	require.Equal(t, zellij.CommandFailed, status.State)
+	require.Contains(t, status.Error, "3 attempts: zellij pipe failed")
+	require.Len(t, sender.Commands(), 3)
+
+	req = httptest.NewRequest("GET", "/events/dead-letters", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var letters DeadLetterListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &letters))
+	require.Len(t, letters.DeadLetters, 1)
+	require.Equal(t, "zellij.create_session", letters.DeadLetters[0].Subscriber)
+	require.Equal(t, session.StateRequested, getSessionState(t, router, "test-session-1"))
+
+	// Re-driving once zellij is back sends the command again
+	sender.FailWith(nil)
+
+	req = httptest.NewRequest("POST", "/events/dead-letters", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, session.StateStarting, getSessionState(t, router, "test-session-1"))
+
+	req = httptest.NewRequest("GET", "/zellij/commands/"+created.CommandID, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, zellij.CommandPending, status.State)
 }
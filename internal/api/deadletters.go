@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// DeadLetterResponse is a dead letter as shown to API clients.
+type DeadLetterResponse struct {
+	ID         string          `json:"id"`
+	Subscriber string          `json:"subscriber"`
+	Type       string          `json:"type"`
+	Data       json.RawMessage `json:"data"`
+	Error      string          `json:"error"`
+	FailedAt   time.Time       `json:"failed_at"`
+	Redrives   int             `json:"redrives"`
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []DeadLetterResponse `json:"dead_letters"`
+}
+
+func NewDeadLetterListResponse(letters []eventbus.DeadLetter) *DeadLetterListResponse {
+	response := &DeadLetterListResponse{DeadLetters: make([]DeadLetterResponse, 0, len(letters))}
+	for _, letter := range letters {
+		data, err := json.Marshal(letter.Event.Data)
+		if err != nil {
+			log.Printf("Failed to encode dead letter %s: %v", letter.ID, err)
+			data = json.RawMessage("null")
+		}
+
+		response.DeadLetters = append(response.DeadLetters, DeadLetterResponse{
+			ID:         letter.ID,
+			Subscriber: letter.Subscriber,
+			Type:       letter.Event.Type,
+			Data:       data,
+			Error:      letter.Err,
+			FailedAt:   letter.FailedAt,
+			Redrives:   letter.Redrives,
+		})
+	}
+	return response
+}
+
+func (dlr *DeadLetterListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+// RedriveRequest selects dead letters to re-drive; no IDs means all of them.
+type RedriveRequest struct {
+	IDs []string `json:"ids"`
+}
+
+func (rr *RedriveRequest) Bind(r *http.Request) error {
+
+	return nil
+}
+
+type RedriveFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+type RedriveResponse struct {
+	Redriven []string         `json:"redriven"`
+	Failed   []RedriveFailure `json:"failed"`
+}
+
+func (rr *RedriveResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+// DeadLetterController exposes the dead-letter queue at /events/dead-letters.
+type DeadLetterController struct {
+	store *eventbus.DeadLetterStore
+}
+
+func NewDeadLetterController(store *eventbus.DeadLetterStore) *DeadLetterController {
+	return &DeadLetterController{
+		store: store,
+	}
+}
+
+func (c *DeadLetterController) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/", c.ListDeadLetters)
+	r.Post("/", c.RedriveDeadLetters)
+
+	return r
+}
+
+func (c *DeadLetterController) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, NewDeadLetterListResponse(c.store.List()))
+}
+
+func (c *DeadLetterController) RedriveDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req := &RedriveRequest{}
+	if err := render.Bind(r, req); err != nil && !errors.Is(err, io.EOF) {
+		render.Render(w, r, common.ErrInvalidRequest(err))
+		return
+	}
+
+	ids := req.IDs
+	if len(ids) == 0 {
+		for _, letter := range c.store.List() {
+			ids = append(ids, letter.ID)
+		}
+	}
+
+	response := &RedriveResponse{Redriven: []string{}, Failed: []RedriveFailure{}}
+	for _, id := range ids {
+		if err := c.store.Redrive(ctx, id); err != nil {
+			if errors.Is(err, eventbus.ErrDeadLetterNotFound) && len(req.IDs) == 1 {
+				render.Render(w, r, common.ErrNotFound())
+				return
+			}
+			response.Failed = append(response.Failed, RedriveFailure{ID: id, Error: err.Error()})
+			continue
+		}
+		response.Redriven = append(response.Redriven, id)
+	}
+
+	render.Render(w, r, response)
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+)
+
+const (
+	defaultStreamBufferSize  = 256
+	defaultHeartbeatInterval = 15 * time.Second
+	streamClientQueueSize    = 64
+
+	// streamResetEvent tells a resuming client that events were lost, either
+	// because they fell out of the buffer or the daemon restarted, and it
+	// should refetch state.
+	streamResetEvent = "stream.reset"
+)
+
+// StreamEvent is a bus event as sent to SSE clients.
+type StreamEvent struct {
+	ID   uint64          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type EventStreamOption func(*EventStream)
+
+// WithStreamBufferSize sets how many recent events are kept for clients
+// resuming with Last-Event-ID.
+func WithStreamBufferSize(size int) EventStreamOption {
+	return func(s *EventStream) {
+		if size > 0 {
+			s.buffer = newRingBuffer(size)
+		}
+	}
+}
+
+func WithHeartbeatInterval(interval time.Duration) EventStreamOption {
+	return func(s *EventStream) {
+		if interval > 0 {
+			s.heartbeat = interval
+		}
+	}
+}
+
+// EventStream bridges every bus event to clients of GET /events as
+// Server-Sent Events.
+type EventStream struct {
+	bus       eventbus.EventBus
+	heartbeat time.Duration
+
+	mu      sync.Mutex
+	sub     eventbus.Subscription
+	buffer  *ringBuffer
+	lastID  uint64
+	clients map[*streamClient]struct{}
+}
+
+type streamClient struct {
+	prefixes []string
+	events   chan StreamEvent
+	// closed is closed when the client must disconnect: it fell behind or
+	// the daemon is shutting down.
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewEventStream(bus eventbus.EventBus, opts ...EventStreamOption) *EventStream {
+	s := &EventStream{
+		bus:       bus,
+		heartbeat: defaultHeartbeatInterval,
+		buffer:    newRingBuffer(defaultStreamBufferSize),
+		clients:   make(map[*streamClient]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *EventStream) OnAppStart(ctx context.Context) error {
+	s.sub = s.bus.Subscribe(eventbus.Wildcard, s.record)
+	return nil
+}
+
+func (s *EventStream) OnAppEnd(ctx context.Context) error {
+	if s.sub != nil {
+		s.sub.Unsubscribe()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		client.close()
+	}
+
+	return nil
+}
+
+func (s *EventStream) record(ctx context.Context, event eventbus.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		log.Printf("Skipping event %s for stream: %v", event.Type, err)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	streamEvent := StreamEvent{ID: s.lastID, Type: event.Type, Data: data}
+	s.buffer.push(streamEvent)
+
+	for client := range s.clients {
+		if !client.wants(event.Type) {
+			continue
+		}
+
+		select {
+		case client.events <- streamEvent:
+		default:
+			// Dropping silently would leave a hole; disconnecting makes the
+			// client resume from its last event ID instead.
+			client.close()
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP streams events as SSE. Clients may filter with one or more
+// ?type= prefixes and resume with the Last-Event-ID header.
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+
+	client := &streamClient{
+		prefixes: parseTypePrefixes(r),
+		events:   make(chan StreamEvent, streamClientQueueSize),
+		closed:   make(chan struct{}),
+	}
+
+	backlog, complete := s.attach(client, r.Header.Get("Last-Event-ID"))
+	defer s.detach(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if !complete {
+		writeStreamEvent(w, StreamEvent{Type: streamResetEvent, Data: json.RawMessage("{}")})
+	}
+	for _, event := range backlog {
+		writeStreamEvent(w, event)
+	}
+	if err := rc.Flush(); err != nil {
+		log.Printf("Event stream not supported: %v", err)
+		return
+	}
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-client.closed:
+			return
+		case event := <-client.events:
+			writeStreamEvent(w, event)
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// attach registers the client and returns the buffered events it missed, in
+// one step so nothing is lost or sent twice in between. complete is false when
+// the requested ID is no longer buffered.
+func (s *EventStream) attach(client *streamClient, lastEventID string) ([]StreamEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clients[client] = struct{}{}
+
+	if lastEventID == "" {
+		return nil, true
+	}
+
+	lastID, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || lastID > s.lastID {
+		// Unknown ID, likely from before a daemon restart
+		return s.filterBacklog(client, 0), false
+	}
+
+	complete := lastID+1 >= s.buffer.oldestID() || s.buffer.len() == 0
+	return s.filterBacklog(client, lastID), complete
+}
+
+func (s *EventStream) filterBacklog(client *streamClient, afterID uint64) []StreamEvent {
+	var backlog []StreamEvent
+	for _, event := range s.buffer.since(afterID) {
+		if client.wants(event.Type) {
+			backlog = append(backlog, event)
+		}
+	}
+	return backlog
+}
+
+func (s *EventStream) detach(client *streamClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.clients, client)
+}
+
+func (c *streamClient) wants(eventType string) bool {
+	if len(c.prefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *streamClient) close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+	})
+}
+
+// parseTypePrefixes accepts ?type=session.&type=workspace. as well as
+// ?type=session.,workspace.
+func parseTypePrefixes(r *http.Request) []string {
+	var prefixes []string
+	for _, value := range r.URL.Query()["type"] {
+		for _, prefix := range strings.Split(value, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+func writeStreamEvent(w http.ResponseWriter, event StreamEvent) {
+	if event.ID != 0 {
+		fmt.Fprintf(w, "id: %d\n", event.ID)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+}
+
+// ringBuffer keeps the most recent events in publish order.
+type ringBuffer struct {
+	items []StreamEvent
+	start int
+	size  int
+}
+
+func newRingBuffer(capacity int) *ringBuffer {
+	return &ringBuffer{items: make([]StreamEvent, capacity)}
+}
+
+func (b *ringBuffer) push(event StreamEvent) {
+	end := (b.start + b.size) % len(b.items)
+	b.items[end] = event
+
+	if b.size < len(b.items) {
+		b.size++
+	} else {
+		b.start = (b.start + 1) % len(b.items)
+	}
+}
+
+func (b *ringBuffer) len() int {
+	return b.size
+}
+
+func (b *ringBuffer) oldestID() uint64 {
+	if b.size == 0 {
+		return 0
+	}
+	return b.items[b.start].ID
+}
+
+// since returns the buffered events with an ID greater than id.
+func (b *ringBuffer) since(id uint64) []StreamEvent {
+	var events []StreamEvent
+	for i := range b.size {
+		event := b.items[(b.start+i)%len(b.items)]
+		if event.ID > id {
+			events = append(events, event)
+		}
+	}
+	return events
+}
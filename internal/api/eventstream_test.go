@@ -0,0 +1,219 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/stretchr/testify/require"
+)
+
+// sseFrame is one parsed Server-Sent Events message
+type sseFrame struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+// setupEventStream creates a stream on its own bus, served over HTTP
+func setupEventStream(t *testing.T, opts ...EventStreamOption) (*EventStream, eventbus.EventBus, *httptest.Server) {
+	t.Helper()
+
+	bus := eventbus.NewEventBus()
+	stream := NewEventStream(bus, opts...)
+	require.NoError(t, stream.OnAppStart(context.Background()))
+
+	server := httptest.NewServer(stream)
+	t.Cleanup(func() {
+		stream.OnAppEnd(context.Background())
+		server.Close()
+	})
+
+	return stream, bus, server
+}
+
+// openStream connects to an SSE endpoint and returns a reader over its body
+func openStream(t *testing.T, url, lastEventID string) *bufio.Reader {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	t.Cleanup(cancel)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	return bufio.NewReader(resp.Body)
+}
+
+// readFrame reads the next message from an SSE stream
+func readFrame(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+
+	var frame sseFrame
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return frame
+		}
+
+		switch {
+		case strings.HasPrefix(line, ":"):
+			frame.Comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id: "):
+			frame.ID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			frame.Event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			frame.Data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// readEvent reads frames until one that isn't a heartbeat
+func readEvent(t *testing.T, reader *bufio.Reader) sseFrame {
+	t.Helper()
+
+	for {
+		if frame := readFrame(t, reader); frame.Comment == "" {
+			return frame
+		}
+	}
+}
+
+func publishSessionCreated(t *testing.T, bus eventbus.EventBus, sessionID string) {
+	t.Helper()
+
+	err := eventbus.Publish(context.Background(), bus, eventbus.SessionCreatedEvent{SessionID: sessionID, WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+}
+
+func TestEventStream_StreamsLiveEvents(t *testing.T) {
+	router, _ := setupTestRouterWithBus(t)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	reader := openStream(t, server.URL+"/events?type=session.", "")
+
+	body := []byte(`{"sessions":[{"name":"live-session","is_current_session":true,"connected_clients":1}]}`)
+	req := httptest.NewRequest("PUT", "/zellij/sessions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	frame := readEvent(t, reader)
+	require.Equal(t, eventbus.SessionCreated, frame.Event)
+	require.NotEmpty(t, frame.ID)
+
+	var data eventbus.SessionCreatedEvent
+	require.NoError(t, json.Unmarshal([]byte(frame.Data), &data))
+	require.Equal(t, "live-session", data.SessionID)
+	require.Equal(t, "attached", data.State)
+}
+
+func TestEventStream_FiltersByPrefix(t *testing.T) {
+	_, bus, server := setupEventStream(t)
+
+	reader := openStream(t, server.URL+"/?type=workspace.,session.died", "")
+
+	publishSessionCreated(t, bus, "session-1")
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.SessionDiedEvent{SessionID: "session-1"}))
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.WorkspaceAddedEvent{WorkspaceID: "ws-1"}))
+
+	require.Equal(t, eventbus.SessionDied, readEvent(t, reader).Event)
+	require.Equal(t, eventbus.WorkspaceAdded, readEvent(t, reader).Event)
+}
+
+func TestEventStream_ResumesFromLastEventID(t *testing.T) {
+	_, bus, server := setupEventStream(t)
+
+	for _, id := range []string{"session-1", "session-2", "session-3"} {
+		publishSessionCreated(t, bus, id)
+	}
+
+	reader := openStream(t, server.URL, "1")
+
+	frame := readEvent(t, reader)
+	require.Equal(t, "2", frame.ID)
+	require.Contains(t, frame.Data, "session-2")
+	require.Equal(t, "3", readEvent(t, reader).ID)
+
+	// Live events follow the backlog
+	publishSessionCreated(t, bus, "session-4")
+	require.Equal(t, "4", readEvent(t, reader).ID)
+}
+
+func TestEventStream_ResetWhenBacklogIsGone(t *testing.T) {
+	_, bus, server := setupEventStream(t, WithStreamBufferSize(2))
+
+	for _, id := range []string{"session-1", "session-2", "session-3", "session-4"} {
+		publishSessionCreated(t, bus, id)
+	}
+
+	reader := openStream(t, server.URL, "1")
+	require.Equal(t, streamResetEvent, readEvent(t, reader).Event)
+	require.Equal(t, "3", readEvent(t, reader).ID)
+	require.Equal(t, "4", readEvent(t, reader).ID)
+
+	// An ID from before a daemon restart
+	reader = openStream(t, server.URL, "99")
+	require.Equal(t, streamResetEvent, readEvent(t, reader).Event)
+	require.Equal(t, "3", readEvent(t, reader).ID)
+}
+
+func TestEventStream_Heartbeat(t *testing.T) {
+	_, _, server := setupEventStream(t, WithHeartbeatInterval(10*time.Millisecond))
+
+	reader := openStream(t, server.URL, "")
+	require.Equal(t, "heartbeat", readFrame(t, reader).Comment)
+}
+
+func TestEventStream_OnAppEndDisconnectsClients(t *testing.T) {
+	stream, _, server := setupEventStream(t)
+
+	reader := openStream(t, server.URL, "")
+	require.NoError(t, stream.OnAppEnd(context.Background()))
+
+	_, err := io.ReadAll(reader)
+	require.NoError(t, err)
+}
+
+func TestRingBuffer(t *testing.T) {
+	buffer := newRingBuffer(3)
+	require.Equal(t, uint64(0), buffer.oldestID())
+
+	for id := uint64(1); id <= 5; id++ {
+		buffer.push(StreamEvent{ID: id})
+	}
+
+	require.Equal(t, 3, buffer.len())
+	require.Equal(t, uint64(3), buffer.oldestID())
+
+	var ids []uint64
+	for _, event := range buffer.since(3) {
+		ids = append(ids, event.ID)
+	}
+	require.Equal(t, []uint64{4, 5}, ids)
+}
@@ -47,6 +47,15 @@ func ErrUnknown(err error) render.Renderer {
 	}
 }
 
+func ErrConflict(err error) render.Renderer {
+	return &ErrResponse{
+		Err:            err,
+		HTTPStatusCode: 409,
+		StatusText:     "Conflict with current state.",
+		ErrorText:      err.Error(),
+	}
+}
+
 func ErrNotFound() render.Renderer {
 	return &ErrResponse{
 		Err:            nil,
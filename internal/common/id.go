@@ -0,0 +1,13 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewID returns a random 16 character hex ID.
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	configDirName  = "utena"
+	configFileName = "config.json"
+
+	// ConfigPathEnv overrides the location of the config file.
+	ConfigPathEnv = "UTENA_CONFIG"
+
+	// WorkspaceRootsEnv overrides the configured workspace roots. It uses the
+	// OS path list separator, e.g. "~/dev:~/projects".
+	WorkspaceRootsEnv = "UTENA_WORKSPACE_ROOTS"
+)
+
+const (
+	// StorageMemory keeps state in memory only; everything is lost on restart.
+	StorageMemory = "memory"
+	// StorageFile keeps state in memory and snapshots sessions to sessions_path.
+	StorageFile = "file"
+	// StorageKV writes every entity to its own file under storage_dir.
+	StorageKV = "kv"
+)
+
+const (
+	// SessionNameCounter generates default names like utena-1, utena-2.
+	SessionNameCounter = "counter"
+	// SessionNameTimestamp generates default names like utena-20260127103000.
+	SessionNameTimestamp = "timestamp"
+)
+
+const (
+	// CommandTransportPipe sends each command with `zellij pipe`.
+	CommandTransportPipe = "pipe"
+	// CommandTransportPoll queues commands for the plugin to long-poll.
+	CommandTransportPoll = "poll"
+)
+
+const (
+	// EventDeliveryAsync hands events to per-subscriber worker goroutines.
+	EventDeliveryAsync = "async"
+	// EventDeliverySync runs handlers on the publisher's goroutine.
+	EventDeliverySync = "sync"
+)
+
+type Config struct {
+	WorkspaceRoots         []string `json:"workspace_roots"`
+	WorkspaceMaxDepth      int      `json:"workspace_max_depth"`
+	WorkspaceIncludeHidden bool     `json:"workspace_include_hidden"`
+	WorkspaceIgnore        []string `json:"workspace_ignore"`
+
+	StorageBackend string `json:"storage_backend"`
+	StorageDir     string `json:"storage_dir"`
+	SessionsPath   string `json:"sessions_path"`
+
+	SessionNameCharset   string `json:"session_name_charset"`
+	SessionNameMaxLength int    `json:"session_name_max_length"`
+	SessionNameStyle     string `json:"session_name_style"`
+	// SessionNameTemplate is a text/template for default session names. When
+	// set it takes precedence over SessionNameStyle.
+	SessionNameTemplate string `json:"session_name_template"`
+
+	EventDelivery  string `json:"event_delivery"`
+	EventQueueSize int    `json:"event_queue_size"`
+
+	// Failed command handlers are retried with exponential backoff before
+	// their event is moved to the dead-letter queue. The sync bus runs
+	// handlers on the publishing request's goroutine, so it doesn't retry
+	// and EventRetryAttempts must be 1 there.
+	EventRetryAttempts     int     `json:"event_retry_attempts"`
+	EventRetryBackoffMs    int     `json:"event_retry_backoff_ms"`
+	EventRetryMaxBackoffMs int     `json:"event_retry_max_backoff_ms"`
+	EventRetryJitter       float64 `json:"event_retry_jitter"`
+	DeadLetterLimit        int     `json:"dead_letter_limit"`
+
+	// CommandTimeoutMs is how long the plugin has to report a command's
+	// outcome before the daemon marks it timed out.
+	CommandTimeoutMs int    `json:"command_timeout_ms"`
+	CommandTransport string `json:"command_transport"`
+	// PipeTimeoutMs bounds a single `zellij pipe` call.
+	PipeTimeoutMs int `json:"pipe_timeout_ms"`
+	// CommandAckTimeoutMs is how long a polled command may go unacknowledged
+	// before it is delivered again.
+	CommandAckTimeoutMs int `json:"command_ack_timeout_ms"`
+	// ReconcileIntervalMs is how often the daemon falls back to
+	// `zellij list-sessions` while no plugin is reporting. 0 disables it.
+	ReconcileIntervalMs int `json:"reconcile_interval_ms"`
+	// SessionUpdateCoalesceMs is the window in which identical plugin
+	// session updates are applied only once. 0 applies every update.
+	SessionUpdateCoalesceMs int `json:"session_update_coalesce_ms"`
+	// HeartbeatIntervalMs is how often plugins are expected to check in.
+	// It must match the plugin's heartbeat interval.
+	HeartbeatIntervalMs int `json:"heartbeat_interval_ms"`
+
+	// JournalPath is the JSONL file every event is appended to. It is rotated
+	// to journal_path.1, .2, ... once it exceeds JournalMaxBytes.
+	JournalPath     string `json:"journal_path"`
+	JournalMaxBytes int64  `json:"journal_max_bytes"`
+	JournalMaxFiles int    `json:"journal_max_files"`
+}
+
+func Default() *Config {
+	return &Config{
+		WorkspaceRoots:    []string{"~/dev"},
+		WorkspaceMaxDepth: 1,
+		WorkspaceIgnore:   []string{"node_modules", "vendor"},
+		StorageBackend:    StorageFile,
+		StorageDir:        "~/.config/utena/store",
+		SessionsPath:      "~/.config/utena/sessions.json",
+
+		SessionNameCharset:   "A-Za-z0-9_-",
+		SessionNameMaxLength: 50,
+		SessionNameStyle:     SessionNameCounter,
+
+		EventDelivery:  EventDeliveryAsync,
+		EventQueueSize: 64,
+
+		EventRetryAttempts:     3,
+		EventRetryBackoffMs:    250,
+		EventRetryMaxBackoffMs: 5000,
+		EventRetryJitter:       0.2,
+		DeadLetterLimit:        100,
+
+		CommandTimeoutMs:    10000,
+		CommandTransport:    CommandTransportPipe,
+		PipeTimeoutMs:       5000,
+		CommandAckTimeoutMs: 30000,
+		ReconcileIntervalMs: 30000,
+
+		SessionUpdateCoalesceMs: 250,
+		HeartbeatIntervalMs:     10000,
+
+		JournalPath:     "~/.config/utena/events.jsonl",
+		JournalMaxBytes: 10 << 20,
+		JournalMaxFiles: 5,
+	}
+}
+
+// Dir returns the utena config directory, ~/.config/utena.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".config", configDirName), nil
+}
+
+// Path returns the config file location, honouring UTENA_CONFIG.
+func Path() (string, error) {
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return ExpandPath(path)
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, configFileName), nil
+}
+
+// Load reads the config file, falling back to defaults when it does not exist.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if roots := os.Getenv(WorkspaceRootsEnv); roots != "" {
+		cfg.WorkspaceRoots = filepath.SplitList(roots)
+	}
+
+	return cfg, nil
+}
+
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	switch c.StorageBackend {
+	case StorageMemory, StorageFile, StorageKV:
+	default:
+		return fmt.Errorf("unknown storage_backend %q", c.StorageBackend)
+	}
+
+	if _, err := regexp.Compile("^[" + c.SessionNameCharset + "]+$"); c.SessionNameCharset == "" || err != nil {
+		return fmt.Errorf("invalid session_name_charset %q", c.SessionNameCharset)
+	}
+
+	if c.SessionNameMaxLength <= 0 {
+		return fmt.Errorf("session_name_max_length must be positive, got %d", c.SessionNameMaxLength)
+	}
+
+	switch c.SessionNameStyle {
+	case SessionNameCounter, SessionNameTimestamp:
+	default:
+		return fmt.Errorf("unknown session_name_style %q", c.SessionNameStyle)
+	}
+
+	if _, err := template.New("session_name").Option("missingkey=error").Parse(c.SessionNameTemplate); err != nil {
+		return fmt.Errorf("invalid session_name_template: %w", err)
+	}
+
+	switch c.EventDelivery {
+	case EventDeliveryAsync, EventDeliverySync:
+	default:
+		return fmt.Errorf("unknown event_delivery %q", c.EventDelivery)
+	}
+
+	if c.EventQueueSize <= 0 {
+		return fmt.Errorf("event_queue_size must be positive, got %d", c.EventQueueSize)
+	}
+
+	if c.EventRetryAttempts <= 0 {
+		return fmt.Errorf("event_retry_attempts must be positive, got %d", c.EventRetryAttempts)
+	}
+
+	if c.EventDelivery == EventDeliverySync && c.EventRetryAttempts > 1 {
+		return fmt.Errorf("event_retry_attempts must be 1 with event_delivery %q, got %d", EventDeliverySync, c.EventRetryAttempts)
+	}
+
+	if c.EventRetryBackoffMs < 0 || c.EventRetryMaxBackoffMs < c.EventRetryBackoffMs {
+		return fmt.Errorf("event_retry_backoff_ms (%d) must be between 0 and event_retry_max_backoff_ms (%d)", c.EventRetryBackoffMs, c.EventRetryMaxBackoffMs)
+	}
+
+	if c.EventRetryJitter < 0 || c.EventRetryJitter > 1 {
+		return fmt.Errorf("event_retry_jitter must be between 0 and 1, got %g", c.EventRetryJitter)
+	}
+
+	if c.DeadLetterLimit <= 0 {
+		return fmt.Errorf("dead_letter_limit must be positive, got %d", c.DeadLetterLimit)
+	}
+
+	if c.CommandTimeoutMs <= 0 {
+		return fmt.Errorf("command_timeout_ms must be positive, got %d", c.CommandTimeoutMs)
+	}
+
+	switch c.CommandTransport {
+	case CommandTransportPipe, CommandTransportPoll:
+	default:
+		return fmt.Errorf("unknown command_transport %q", c.CommandTransport)
+	}
+
+	if c.PipeTimeoutMs <= 0 {
+		return fmt.Errorf("pipe_timeout_ms must be positive, got %d", c.PipeTimeoutMs)
+	}
+
+	if c.ReconcileIntervalMs < 0 {
+		return fmt.Errorf("reconcile_interval_ms must not be negative, got %d", c.ReconcileIntervalMs)
+	}
+
+	if c.SessionUpdateCoalesceMs < 0 {
+		return fmt.Errorf("session_update_coalesce_ms must not be negative, got %d", c.SessionUpdateCoalesceMs)
+	}
+
+	if c.HeartbeatIntervalMs <= 0 {
+		return fmt.Errorf("heartbeat_interval_ms must be positive, got %d", c.HeartbeatIntervalMs)
+	}
+
+	if c.CommandAckTimeoutMs <= 0 {
+		return fmt.Errorf("command_ack_timeout_ms must be positive, got %d", c.CommandAckTimeoutMs)
+	}
+
+	if c.JournalPath == "" {
+		return errors.New("journal_path cannot be empty")
+	}
+
+	if c.JournalMaxBytes <= 0 {
+		return fmt.Errorf("journal_max_bytes must be positive, got %d", c.JournalMaxBytes)
+	}
+
+	if c.JournalMaxFiles < 0 {
+		return fmt.Errorf("journal_max_files cannot be negative, got %d", c.JournalMaxFiles)
+	}
+
+	return nil
+}
+
+// ExpandPath expands a leading ~ to the user's home directory and returns an
+// absolute, cleaned path.
+func ExpandPath(path string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve home directory: %w", err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+
+	return filepath.Abs(path)
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadFile_Missing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "config.json"))
+	require.NoError(t, err)
+	require.Equal(t, Default(), cfg)
+}
+
+func TestLoadFile_OverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"workspace_roots": ["~/projects"], "workspace_max_depth": 2}`), 0o644)
+	require.NoError(t, err)
+
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"~/projects"}, cfg.WorkspaceRoots)
+	require.Equal(t, 2, cfg.WorkspaceMaxDepth)
+	require.Equal(t, Default().WorkspaceIgnore, cfg.WorkspaceIgnore)
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{`), 0o644)
+	require.NoError(t, err)
+
+	_, err = LoadFile(path)
+	require.Error(t, err)
+}
+
+func TestLoadFile_UnknownStorageBackend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{"storage_backend": "postgres"}`), 0o644)
+	require.NoError(t, err)
+
+	_, err = LoadFile(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "storage_backend")
+}
+
+func TestLoadFile_SyncDeliveryRejectsRetries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"event_delivery": "sync"}`), 0o644))
+
+	// The default retry attempts don't apply to the sync bus
+	_, err := LoadFile(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "event_retry_attempts")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"event_delivery": "sync", "event_retry_attempts": 1}`), 0o644))
+	cfg, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, EventDeliverySync, cfg.EventDelivery)
+}
+
+func TestLoadFile_InvalidSessionNameRules(t *testing.T) {
+	tests := map[string]string{
+		"session_name_charset":    `{"session_name_charset": "z-a"}`,
+		"session_name_max_length": `{"session_name_max_length": 0}`,
+		"session_name_style":      `{"session_name_style": "random"}`,
+		"session_name_template":   `{"session_name_template": "{{.Workspace"}`,
+		"event_delivery":          `{"event_delivery": "carrier-pigeon"}`,
+		"event_queue_size":        `{"event_queue_size": -1}`,
+		"event_retry_attempts":    `{"event_retry_attempts": 0}`,
+		"event_retry_backoff_ms":  `{"event_retry_backoff_ms": 10000}`,
+		"event_retry_jitter":      `{"event_retry_jitter": 1.5}`,
+		"dead_letter_limit":       `{"dead_letter_limit": 0}`,
+		"command_timeout_ms":      `{"command_timeout_ms": 0}`,
+		"command_transport":       `{"command_transport": "smoke-signal"}`,
+		"command_ack_timeout_ms":  `{"command_ack_timeout_ms": 0}`,
+		"pipe_timeout_ms":         `{"pipe_timeout_ms": 0}`,
+		"reconcile_interval_ms":   `{"reconcile_interval_ms": -1}`,
+		"session_update_coalesce": `{"session_update_coalesce_ms": -1}`,
+		"heartbeat_interval_ms":   `{"heartbeat_interval_ms": 0}`,
+		"journal_path":            `{"journal_path": ""}`,
+		"journal_max_bytes":       `{"journal_max_bytes": 0}`,
+		"journal_max_files":       `{"journal_max_files": -1}`,
+	}
+
+	for field, contents := range tests {
+		t.Run(field, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+			_, err := LoadFile(path)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), field)
+		})
+	}
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	err := os.WriteFile(path, []byte(`{"workspace_roots": ["~/projects"]}`), 0o644)
+	require.NoError(t, err)
+
+	t.Setenv(ConfigPathEnv, path)
+	t.Setenv(WorkspaceRootsEnv, "/a"+string(os.PathListSeparator)+"/b")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, []string{"/a", "/b"}, cfg.WorkspaceRoots)
+}
+
+func TestExpandPath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	expanded, err := ExpandPath("~/dev")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(home, "dev"), expanded)
+
+	expanded, err = ExpandPath("/tmp/../tmp/dev")
+	require.NoError(t, err)
+	require.Equal(t, "/tmp/dev", expanded)
+}
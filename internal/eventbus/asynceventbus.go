@@ -0,0 +1,200 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const defaultQueueSize = 64
+
+var (
+	ErrBusClosed       = errors.New("event bus is closed")
+	ErrQueueFull       = errors.New("subscriber queue is full, event dropped")
+	ErrHandlerPanicked = errors.New("event handler panicked")
+)
+
+// ErrorHandler receives failures from asynchronous delivery: handler errors,
+// recovered panics and events dropped because a queue was full.
+type ErrorHandler func(event Event, err error)
+
+type AsyncOption func(*AsyncEventBus)
+
+// WithQueueSize bounds how many events each subscriber may have pending.
+func WithQueueSize(size int) AsyncOption {
+	return func(bus *AsyncEventBus) {
+		if size > 0 {
+			bus.queueSize = size
+		}
+	}
+}
+
+func WithErrorHandler(handler ErrorHandler) AsyncOption {
+	return func(bus *AsyncEventBus) {
+		bus.onError = handler
+	}
+}
+
+// AsyncEventBus delivers events on a worker goroutine per subscription, so a
+// slow or failing subscriber neither blocks the publisher nor the other
+// subscribers. Events for one subscriber are handled in publish order.
+type AsyncEventBus struct {
+	mu            sync.RWMutex
+	subscriptions []*subscription
+	closed        bool
+	workers       sync.WaitGroup
+
+	queueSize int
+	onError   ErrorHandler
+}
+
+type subscription struct {
+	bus     *AsyncEventBus
+	pattern string
+	handler Handler
+	queue   chan queuedEvent
+}
+
+type queuedEvent struct {
+	ctx   context.Context
+	event Event
+}
+
+func NewAsyncEventBus(opts ...AsyncOption) *AsyncEventBus {
+	bus := &AsyncEventBus{
+		queueSize: defaultQueueSize,
+		onError: func(event Event, err error) {
+			log.Printf("Event %s: %v", event.Type, err)
+		},
+	}
+
+	for _, opt := range opts {
+		opt(bus)
+	}
+
+	return bus
+}
+
+func (bus *AsyncEventBus) Subscribe(pattern string, handler Handler) Subscription {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	sub := &subscription{
+		bus:     bus,
+		pattern: pattern,
+		handler: handler,
+		queue:   make(chan queuedEvent, bus.queueSize),
+	}
+
+	// A closed bus delivers nothing, so there's no worker to start
+	if bus.closed {
+		close(sub.queue)
+		return sub
+	}
+
+	bus.subscriptions = append(bus.subscriptions, sub)
+
+	bus.workers.Add(1)
+	go bus.run(sub)
+	return sub
+}
+
+// Publish queues the event for every subscriber and returns immediately.
+// Handler outcomes are reported through the ErrorHandler, not returned; a
+// subscriber whose queue is full misses the event, which is reported through
+// both and returned as ErrQueueFull.
+func (bus *AsyncEventBus) Publish(ctx context.Context, event Event) error {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	if bus.closed {
+		return ErrBusClosed
+	}
+
+	// Stamp before queueing so a backed up subscriber sees when the event
+	// happened, not when it got to it
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	// Handlers outlive the request that published the event
+	queued := queuedEvent{ctx: context.WithoutCancel(ctx), event: event}
+	var errs []error
+	for _, sub := range bus.subscriptions {
+		if !MatchPattern(sub.pattern, event.Type) {
+			continue
+		}
+
+		select {
+		case sub.queue <- queued:
+		default:
+			bus.onError(event, ErrQueueFull)
+			errs = append(errs, fmt.Errorf("%w: subscriber %s", ErrQueueFull, sub.pattern))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops accepting events and waits for the queues to drain.
+func (bus *AsyncEventBus) Close(ctx context.Context) error {
+	bus.mu.Lock()
+	if !bus.closed {
+		bus.closed = true
+		for _, sub := range bus.subscriptions {
+			close(sub.queue)
+		}
+		bus.subscriptions = nil
+	}
+	bus.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		bus.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("event bus did not drain: %w", ctx.Err())
+	}
+}
+
+// Unsubscribe stops new deliveries. Events already queued are still handled.
+func (s *subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for i, sub := range s.bus.subscriptions {
+		if sub == s {
+			s.bus.subscriptions = append(s.bus.subscriptions[:i:i], s.bus.subscriptions[i+1:]...)
+			close(s.queue)
+			return
+		}
+	}
+}
+
+func (bus *AsyncEventBus) run(sub *subscription) {
+	defer bus.workers.Done()
+
+	for queued := range sub.queue {
+		if err := bus.deliver(sub.handler, queued); err != nil {
+			bus.onError(queued.event, err)
+		}
+	}
+}
+
+func (bus *AsyncEventBus) deliver(handler Handler, queued queuedEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrHandlerPanicked, r)
+		}
+	}()
+
+	return handler(queued.ctx, queued.event)
+}
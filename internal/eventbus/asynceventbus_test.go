@@ -0,0 +1,223 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// errorRecorder collects errors reported by an async bus
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) record(event Event, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+// setupAsyncBus creates an async bus that is closed when the test ends
+func setupAsyncBus(t *testing.T, opts ...AsyncOption) (*AsyncEventBus, *errorRecorder) {
+	t.Helper()
+
+	recorder := &errorRecorder{}
+	bus := NewAsyncEventBus(append([]AsyncOption{WithErrorHandler(recorder.record)}, opts...)...)
+	t.Cleanup(func() {
+		bus.Close(context.Background())
+	})
+	return bus, recorder
+}
+
+func closeBus(t *testing.T, bus EventBus) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, bus.Close(ctx))
+}
+
+func TestAsyncEventBus_DeliversInOrder(t *testing.T) {
+	bus, recorder := setupAsyncBus(t)
+
+	var received []int
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		received = append(received, event.Data.(int))
+		return nil
+	})
+
+	for i := range 10 {
+		require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event", Data: i}))
+	}
+	closeBus(t, bus)
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, received)
+	require.Empty(t, recorder.errors())
+}
+
+func TestAsyncEventBus_StampsPublishTime(t *testing.T) {
+	bus, _ := setupAsyncBus(t)
+
+	release := make(chan struct{})
+	var stamped time.Time
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		<-release
+		stamped = event.Time
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+	published := time.Now()
+
+	// The subscriber gets to the event later, but sees when it was published
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	closeBus(t, bus)
+
+	require.False(t, stamped.IsZero())
+	require.False(t, stamped.After(published))
+}
+
+func TestAsyncEventBus_SlowSubscriberDoesNotBlock(t *testing.T) {
+	bus, _ := setupAsyncBus(t)
+
+	release := make(chan struct{})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		<-release
+		return nil
+	})
+
+	delivered := make(chan struct{})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		close(delivered)
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("fast subscriber was blocked by the slow one")
+	}
+
+	close(release)
+}
+
+func TestAsyncEventBus_IsolatesFailures(t *testing.T) {
+	bus, recorder := setupAsyncBus(t)
+
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		return errors.New("subscriber failed")
+	})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		panic("boom")
+	})
+
+	var delivered bool
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		delivered = true
+		return nil
+	})
+
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+	closeBus(t, bus)
+
+	require.True(t, delivered)
+
+	errs := recorder.errors()
+	require.Len(t, errs, 2)
+	require.ErrorContains(t, errors.Join(errs...), "subscriber failed")
+	require.ErrorIs(t, errors.Join(errs...), ErrHandlerPanicked)
+}
+
+func TestAsyncEventBus_DropsWhenQueueFull(t *testing.T) {
+	bus, recorder := setupAsyncBus(t, WithQueueSize(1))
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+
+	// The first event occupies the worker, the second fills the queue
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+	<-started
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+	require.ErrorIs(t, bus.Publish(context.Background(), Event{Type: "test.event"}), ErrQueueFull)
+
+	errs := recorder.errors()
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], ErrQueueFull)
+
+	close(release)
+}
+
+func TestAsyncEventBus_HandlerContextOutlivesPublisher(t *testing.T) {
+	bus, _ := setupAsyncBus(t)
+
+	var handlerErr error
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		handlerErr = ctx.Err()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, bus.Publish(ctx, Event{Type: "test.event"}))
+	cancel()
+	closeBus(t, bus)
+
+	require.NoError(t, handlerErr)
+}
+
+func TestAsyncEventBus_Close(t *testing.T) {
+	bus, _ := setupAsyncBus(t)
+
+	release := make(chan struct{})
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		<-release
+		return nil
+	})
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+
+	// Gives up when the handler doesn't finish in time
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, bus.Close(ctx), context.DeadlineExceeded)
+
+	close(release)
+	closeBus(t, bus)
+
+	require.ErrorIs(t, bus.Publish(context.Background(), Event{Type: "test.event"}), ErrBusClosed)
+}
+
+func TestInMemoryEventBus_RunsEveryHandler(t *testing.T) {
+	bus := NewEventBus()
+
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		return errors.New("first failed")
+	})
+
+	var delivered bool
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		delivered = true
+		return nil
+	})
+
+	err := bus.Publish(context.Background(), Event{Type: "test.event"})
+	require.ErrorContains(t, err, "first failed")
+	require.True(t, delivered)
+}
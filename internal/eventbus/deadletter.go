@@ -0,0 +1,151 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultDeadLetterLimit = 100
+
+var (
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrRedriveInProgress  = errors.New("dead letter is already being re-driven")
+)
+
+// DeadLetter is an event a subscriber gave up on.
+type DeadLetter struct {
+	ID         string
+	Subscriber string
+	Event      Event
+	Err        string
+	FailedAt   time.Time
+	// Redrives counts how often the event was re-driven and failed again.
+	Redrives int
+
+	handler   Handler
+	redriving bool
+}
+
+// DeadLetterStore keeps events whose handler failed for good, along with the
+// handler, so they can be inspected and re-driven. Only the most recent
+// limit letters are kept.
+type DeadLetterStore struct {
+	mu      sync.Mutex
+	letters []*DeadLetter
+	nextID  uint64
+	limit   int
+	now     func() time.Time
+}
+
+func NewDeadLetterStore(limit int) *DeadLetterStore {
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+
+	return &DeadLetterStore{
+		limit: limit,
+		now:   time.Now,
+	}
+}
+
+// Middleware records events the wrapped handler fails on under subscriber.
+// The error is still returned. Place it outside Retry so only exhausted
+// events are captured.
+func (s *DeadLetterStore) Middleware(subscriber string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event Event) error {
+			err := next(ctx, event)
+			if err != nil {
+				s.add(subscriber, event, err, next)
+			}
+			return err
+		}
+	}
+}
+
+// List returns the dead letters, oldest first.
+func (s *DeadLetterStore) List() []DeadLetter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letters := make([]DeadLetter, len(s.letters))
+	for i, letter := range s.letters {
+		letters[i] = *letter
+	}
+	return letters
+}
+
+// Redrive runs the letter's handler again. On success the letter is removed;
+// on failure it stays with the new error.
+func (s *DeadLetterStore) Redrive(ctx context.Context, id string) error {
+	s.mu.Lock()
+	letter, ok := s.find(id)
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrDeadLetterNotFound, id)
+	}
+	if letter.redriving {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrRedriveInProgress, id)
+	}
+	letter.redriving = true
+	s.mu.Unlock()
+
+	err := letter.handler(ctx, letter.Event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	letter.redriving = false
+
+	if err != nil {
+		letter.Err = err.Error()
+		letter.FailedAt = s.now()
+		letter.Redrives++
+		return err
+	}
+
+	s.remove(letter)
+	return nil
+}
+
+func (s *DeadLetterStore) add(subscriber string, event Event, err error, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.letters = append(s.letters, &DeadLetter{
+		ID:         strconv.FormatUint(s.nextID, 10),
+		Subscriber: subscriber,
+		Event:      event,
+		Err:        err.Error(),
+		FailedAt:   s.now(),
+		handler:    handler,
+	})
+
+	if len(s.letters) > s.limit {
+		s.letters = s.letters[len(s.letters)-s.limit:]
+	}
+}
+
+func (s *DeadLetterStore) find(id string) (*DeadLetter, bool) {
+	for _, letter := range s.letters {
+		if letter.ID == id {
+			return letter, true
+		}
+	}
+	return nil, false
+}
+
+func (s *DeadLetterStore) remove(target *DeadLetter) {
+	for i, letter := range s.letters {
+		if letter == target {
+			s.letters = append(s.letters[:i:i], s.letters[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupDeadLetterHandler wraps a flaky handler in retries and dead-lettering
+func setupDeadLetterHandler(t *testing.T, failures int) (*DeadLetterStore, Handler, *int) {
+	t.Helper()
+
+	store := NewDeadLetterStore(10)
+	calls := new(int)
+	handler := Chain(flakyHandler(failures, calls), store.Middleware("test.subscriber"), Retry(fastRetryPolicy(2)))
+	return store, handler, calls
+}
+
+func TestDeadLetterStore_CapturesExhaustedEvents(t *testing.T) {
+	store, handler, calls := setupDeadLetterHandler(t, 2)
+
+	event := Event{Type: "test.event", Data: typedTestEvent{Value: "hello"}}
+	require.Error(t, handler(context.Background(), event))
+	require.Equal(t, 2, *calls)
+
+	letters := store.List()
+	require.Len(t, letters, 1)
+	require.Equal(t, "test.subscriber", letters[0].Subscriber)
+	require.Equal(t, event, letters[0].Event)
+	require.Contains(t, letters[0].Err, "zellij pipe failed")
+}
+
+func TestDeadLetterStore_SuccessIsNotCaptured(t *testing.T) {
+	store, handler, _ := setupDeadLetterHandler(t, 1)
+
+	require.NoError(t, handler(context.Background(), Event{Type: "test.event"}))
+	require.Empty(t, store.List())
+}
+
+func TestDeadLetterStore_Redrive(t *testing.T) {
+	store, handler, calls := setupDeadLetterHandler(t, 4)
+	require.Error(t, handler(context.Background(), Event{Type: "test.event"}))
+
+	id := store.List()[0].ID
+
+	// Still failing: the letter stays with the redrive counted
+	require.Error(t, store.Redrive(context.Background(), id))
+	require.Equal(t, 4, *calls)
+	require.Equal(t, 1, store.List()[0].Redrives)
+
+	require.NoError(t, store.Redrive(context.Background(), id))
+	require.Empty(t, store.List())
+}
+
+func TestDeadLetterStore_Redrive_NotFound(t *testing.T) {
+	store := NewDeadLetterStore(10)
+	require.ErrorIs(t, store.Redrive(context.Background(), "42"), ErrDeadLetterNotFound)
+}
+
+func TestDeadLetterStore_Limit(t *testing.T) {
+	store := NewDeadLetterStore(2)
+	calls := new(int)
+	handler := store.Middleware("test.subscriber")(flakyHandler(10, calls))
+
+	for range 3 {
+		handler(context.Background(), Event{Type: "test.event"})
+	}
+
+	letters := store.List()
+	require.Len(t, letters, 2)
+	require.Equal(t, "2", letters[0].ID)
+	require.Equal(t, "3", letters[1].ID)
+}
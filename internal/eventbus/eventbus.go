@@ -2,49 +2,121 @@ package eventbus
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync"
+	"time"
 )
 
+// Wildcard matches every event type when used as a subscription pattern.
+const Wildcard = "*"
+
 type Event struct {
 	Type string
 	Data interface{}
+	// Time is when the event was published. Buses stamp it on Publish unless
+	// the publisher already set it.
+	Time time.Time
 }
 
 type Handler func(ctx context.Context, event Event) error
 
 type EventBus interface {
-	Subscribe(eventType string, handler Handler)
+	// Subscribe registers handler for events matching pattern: an exact type
+	// such as "session.created", a prefix such as "session.*", or "*".
+	Subscribe(pattern string, handler Handler) Subscription
 	Publish(ctx context.Context, event Event) error
+	// Close stops accepting events and waits for queued ones to be handled,
+	// giving up when ctx is done.
+	Close(ctx context.Context) error
+}
+
+// Subscription detaches a handler from the bus. Unsubscribe is safe to call
+// more than once.
+type Subscription interface {
+	Unsubscribe()
+}
+
+// MatchPattern reports whether eventType matches a subscription pattern.
+// "session.*" matches "session.created" and "session.foo.bar" but not
+// "session" itself.
+func MatchPattern(pattern, eventType string) bool {
+	if pattern == Wildcard {
+		return true
+	}
+
+	if prefix, ok := strings.CutSuffix(pattern, ".*"); ok {
+		return strings.HasPrefix(eventType, prefix+".")
+	}
+
+	return pattern == eventType
 }
 
+// InMemoryEventBus runs handlers synchronously on the publisher's goroutine,
+// so Publish returns only after every handler finished. Tests rely on this to
+// observe side effects right after publishing.
 type InMemoryEventBus struct {
 	mu       sync.RWMutex
-	handlers map[string][]Handler
+	handlers []*syncSubscription
+}
+
+type syncSubscription struct {
+	bus     *InMemoryEventBus
+	pattern string
+	handler Handler
 }
 
 func NewEventBus() *InMemoryEventBus {
-	return &InMemoryEventBus{
-		handlers: make(map[string][]Handler),
-	}
+	return &InMemoryEventBus{}
 }
 
-func (bus *InMemoryEventBus) Subscribe(eventType string, handler Handler) {
+func (bus *InMemoryEventBus) Subscribe(pattern string, handler Handler) Subscription {
 	bus.mu.Lock()
 	defer bus.mu.Unlock()
 
-	bus.handlers[eventType] = append(bus.handlers[eventType], handler)
+	sub := &syncSubscription{bus: bus, pattern: pattern, handler: handler}
+	bus.handlers = append(bus.handlers, sub)
+	return sub
 }
 
+// Publish runs every matching handler even when an earlier one fails, and
+// returns the joined errors.
 func (bus *InMemoryEventBus) Publish(ctx context.Context, event Event) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
 	bus.mu.RLock()
-	handlers := bus.handlers[event.Type]
+	var handlers []Handler
+	for _, sub := range bus.handlers {
+		if MatchPattern(sub.pattern, event.Type) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
 	bus.mu.RUnlock()
 
+	var errs []error
 	for _, handler := range handlers {
 		if err := handler(ctx, event); err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
 
+	return errors.Join(errs...)
+}
+
+func (bus *InMemoryEventBus) Close(ctx context.Context) error {
 	return nil
 }
+
+func (s *syncSubscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for i, sub := range s.bus.handlers {
+		if sub == s {
+			s.bus.handlers = append(s.bus.handlers[:i:i], s.bus.handlers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		expected  bool
+	}{
+		{pattern: "session.created", eventType: "session.created", expected: true},
+		{pattern: "session.created", eventType: "session.deleted", expected: false},
+		{pattern: "session.*", eventType: "session.created", expected: true},
+		{pattern: "session.*", eventType: "session.state.changed", expected: true},
+		{pattern: "session.*", eventType: "session", expected: false},
+		{pattern: "session.*", eventType: "sessions.created", expected: false},
+		{pattern: "session.*", eventType: "workspace.added", expected: false},
+		{pattern: "*", eventType: "workspace.added", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.eventType, func(t *testing.T) {
+			require.Equal(t, tt.expected, MatchPattern(tt.pattern, tt.eventType))
+		})
+	}
+}
+
+// busImplementations returns a fresh instance of each bus for shared tests
+func busImplementations(t *testing.T) map[string]EventBus {
+	t.Helper()
+
+	async, _ := setupAsyncBus(t)
+	return map[string]EventBus{
+		"sync":  NewEventBus(),
+		"async": async,
+	}
+}
+
+func TestEventBus_WildcardSubscriptions(t *testing.T) {
+	for name, bus := range busImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			var sessionEvents, allEvents []string
+			bus.Subscribe("session.*", func(ctx context.Context, event Event) error {
+				sessionEvents = append(sessionEvents, event.Type)
+				return nil
+			})
+			bus.Subscribe(Wildcard, func(ctx context.Context, event Event) error {
+				allEvents = append(allEvents, event.Type)
+				return nil
+			})
+
+			ctx := context.Background()
+			require.NoError(t, bus.Publish(ctx, Event{Type: SessionCreated}))
+			require.NoError(t, bus.Publish(ctx, Event{Type: WorkspaceAdded}))
+			require.NoError(t, bus.Publish(ctx, Event{Type: SessionDied}))
+			closeBus(t, bus)
+
+			require.Equal(t, []string{SessionCreated, SessionDied}, sessionEvents)
+			require.Equal(t, []string{SessionCreated, WorkspaceAdded, SessionDied}, allEvents)
+		})
+	}
+}
+
+func TestEventBus_Unsubscribe(t *testing.T) {
+	for name, bus := range busImplementations(t) {
+		t.Run(name, func(t *testing.T) {
+			var kept, removed int
+			bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+				kept++
+				return nil
+			})
+			sub := bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+				removed++
+				return nil
+			})
+
+			ctx := context.Background()
+			require.NoError(t, bus.Publish(ctx, Event{Type: "test.event"}))
+
+			sub.Unsubscribe()
+			sub.Unsubscribe()
+
+			require.NoError(t, bus.Publish(ctx, Event{Type: "test.event"}))
+			closeBus(t, bus)
+
+			require.Equal(t, 2, kept)
+			require.Equal(t, 1, removed)
+		})
+	}
+}
+
+func TestEventBus_UnsubscribeTyped(t *testing.T) {
+	bus := NewEventBus()
+
+	var received int
+	sub := Subscribe(bus, func(ctx context.Context, payload typedTestEvent) error {
+		received++
+		return nil
+	})
+
+	ctx := context.Background()
+	require.NoError(t, Publish(ctx, bus, typedTestEvent{}))
+	sub.Unsubscribe()
+	require.NoError(t, Publish(ctx, bus, typedTestEvent{}))
+
+	require.Equal(t, 1, received)
+}
+
+func TestEventBus_KeepsPublisherTime(t *testing.T) {
+	bus := NewEventBus()
+
+	var stamped []time.Time
+	bus.Subscribe("test.event", func(ctx context.Context, event Event) error {
+		stamped = append(stamped, event.Time)
+		return nil
+	})
+
+	published := time.Date(2026, 1, 27, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event", Time: published}))
+	require.NoError(t, bus.Publish(context.Background(), Event{Type: "test.event"}))
+
+	require.Len(t, stamped, 2)
+	require.Equal(t, published, stamped[0])
+	require.False(t, stamped[1].IsZero())
+}
+
+func TestAsyncEventBus_UnsubscribeAfterClose(t *testing.T) {
+	bus, _ := setupAsyncBus(t)
+
+	sub := bus.Subscribe("test.event", func(ctx context.Context, event Event) error { return nil })
+	closeBus(t, bus)
+
+	require.NotPanics(t, sub.Unsubscribe)
+	require.NotPanics(t, bus.Subscribe("test.event", func(ctx context.Context, event Event) error { return nil }).Unsubscribe)
+}
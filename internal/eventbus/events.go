@@ -1,10 +1,122 @@
 package eventbus
 
+import "time"
+
+// Commands ask another module to do something.
 const (
-	SessionCreateRequested = "session.create_requested"
+	SessionCreateRequested   = "session.create_requested"
+	SessionActivateRequested = "session.activate_requested"
 )
 
+// Domain events report changes that already happened. They are only published
+// when something actually changed, never for a repeated plugin update.
+const (
+	SessionCreated          = "session.created"
+	SessionStateChanged     = "session.state_changed"
+	SessionAttached         = "session.attached"
+	SessionDetached         = "session.detached"
+	SessionDied             = "session.died"
+	SessionWorkspaceChanged = "session.workspace_changed"
+	SessionDeleted          = "session.deleted"
+
+	WorkspaceAdded   = "workspace.added"
+	WorkspaceUpdated = "workspace.updated"
+	WorkspaceRemoved = "workspace.removed"
+
+	ZellijConnectivityChanged = "zellij.connectivity_changed"
+)
+
+// CommandID identifies the resulting Zellij command, so callers can look up
+// whether the plugin actually carried it out.
 type SessionCreateRequestedEvent struct {
-	SessionName   string
-	WorkspacePath string
+	CommandID     string `json:"command_id"`
+	SessionName   string `json:"session_name"`
+	WorkspaceID   string `json:"workspace_id"`
+	WorkspaceName string `json:"workspace_name"`
+	WorkspacePath string `json:"workspace_path"`
+}
+
+type SessionActivateRequestedEvent struct {
+	CommandID   string `json:"command_id"`
+	SessionName string `json:"session_name"`
+}
+
+type SessionCreatedEvent struct {
+	SessionID   string `json:"session_id"`
+	WorkspaceID string `json:"workspace_id"`
+	State       string `json:"state"`
+}
+
+type SessionStateChangedEvent struct {
+	SessionID string `json:"session_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+type SessionAttachedEvent struct {
+	SessionID string `json:"session_id"`
+}
+
+type SessionDetachedEvent struct {
+	SessionID string `json:"session_id"`
+}
+
+type SessionDiedEvent struct {
+	SessionID string `json:"session_id"`
 }
+
+type SessionWorkspaceChangedEvent struct {
+	SessionID string `json:"session_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+type SessionDeletedEvent struct {
+	SessionID   string `json:"session_id"`
+	WorkspaceID string `json:"workspace_id"`
+}
+
+type WorkspaceAddedEvent struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+}
+
+type WorkspaceUpdatedEvent struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+}
+
+type WorkspaceRemovedEvent struct {
+	WorkspaceID string `json:"workspace_id"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+}
+
+// ZellijConnectivityChangedEvent reports the plugin integration moving
+// between connected, degraded and disconnected.
+type ZellijConnectivityChangedEvent struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// EventType ties each payload to the event it is published as.
+
+func (SessionCreateRequestedEvent) EventType() string   { return SessionCreateRequested }
+func (SessionActivateRequestedEvent) EventType() string { return SessionActivateRequested }
+
+func (SessionCreatedEvent) EventType() string          { return SessionCreated }
+func (SessionStateChangedEvent) EventType() string     { return SessionStateChanged }
+func (SessionAttachedEvent) EventType() string         { return SessionAttached }
+func (SessionDetachedEvent) EventType() string         { return SessionDetached }
+func (SessionDiedEvent) EventType() string             { return SessionDied }
+func (SessionWorkspaceChangedEvent) EventType() string { return SessionWorkspaceChanged }
+func (SessionDeletedEvent) EventType() string          { return SessionDeleted }
+
+func (WorkspaceAddedEvent) EventType() string   { return WorkspaceAdded }
+func (WorkspaceUpdatedEvent) EventType() string { return WorkspaceUpdated }
+func (WorkspaceRemovedEvent) EventType() string { return WorkspaceRemoved }
+
+func (ZellijConnectivityChangedEvent) EventType() string { return ZellijConnectivityChanged }
@@ -0,0 +1,111 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrRetriesExhausted = errors.New("event handler failed after retries")
+
+// Middleware wraps a handler, e.g. to retry it or capture its failures.
+type Middleware func(next Handler) Handler
+
+// Chain wraps handler so the first middleware is the outermost.
+func Chain(handler Handler, mws ...Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// RetryPolicy controls how often and how fast a failing handler is retried.
+// The wait before retry n is InitialBackoff * Multiplier^(n-1), capped at
+// MaxBackoff and spread by ±Jitter (a fraction of the wait).
+type RetryPolicy struct {
+	// Attempts is the total number of calls, including the first.
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:       3,
+		InitialBackoff: 250 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the wait before the given retry (1 for the first retry).
+// random is in [0, 1) and picks where in the jitter range the wait falls.
+func (p RetryPolicy) Backoff(retry int, random float64) time.Duration {
+	multiplier := max(p.Multiplier, 1)
+	wait := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 {
+		wait = min(wait, float64(p.MaxBackoff))
+	}
+
+	wait *= 1 + p.Jitter*(2*random-1)
+	return time.Duration(max(wait, 0))
+}
+
+// Retry calls the handler up to policy.Attempts times, backing off between
+// attempts. Errors marked with Permanent, and payload mismatches, are
+// returned without retrying.
+func Retry(policy RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, event Event) error {
+			attempts := max(policy.Attempts, 1)
+
+			var err error
+			for attempt := 1; ; attempt++ {
+				if err = next(ctx, event); err == nil || isPermanent(err) {
+					return err
+				}
+
+				if attempt == attempts {
+					break
+				}
+
+				timer := time.NewTimer(policy.Backoff(attempt, rand.Float64()))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return fmt.Errorf("%w: gave up after %d attempts: %w", ErrRetriesExhausted, attempt, err)
+				}
+			}
+
+			return fmt.Errorf("%w: %d attempts: %w", ErrRetriesExhausted, attempts, err)
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. because the handler's side
+// effect already happened.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent) || errors.Is(err, ErrPayloadMismatch)
+}
@@ -0,0 +1,120 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fastRetryPolicy retries without waiting
+func fastRetryPolicy(attempts int) RetryPolicy {
+	return RetryPolicy{Attempts: attempts}
+}
+
+// flakyHandler fails the first failures calls
+func flakyHandler(failures int, calls *int) Handler {
+	return func(ctx context.Context, event Event) error {
+		*calls++
+		if *calls <= failures {
+			return errors.New("zellij pipe failed")
+		}
+		return nil
+	}
+}
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	wrap := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, event Event) error {
+				order = append(order, name)
+				return next(ctx, event)
+			}
+		}
+	}
+
+	handler := Chain(func(ctx context.Context, event Event) error {
+		order = append(order, "handler")
+		return nil
+	}, wrap("outer"), wrap("inner"))
+
+	require.NoError(t, handler(context.Background(), Event{Type: "test.event"}))
+	require.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	var calls int
+	handler := Retry(fastRetryPolicy(3))(flakyHandler(2, &calls))
+
+	require.NoError(t, handler(context.Background(), Event{Type: "test.event"}))
+	require.Equal(t, 3, calls)
+}
+
+func TestRetry_Exhausted(t *testing.T) {
+	var calls int
+	handler := Retry(fastRetryPolicy(3))(flakyHandler(5, &calls))
+
+	err := handler(context.Background(), Event{Type: "test.event"})
+	require.ErrorIs(t, err, ErrRetriesExhausted)
+	require.Contains(t, err.Error(), "zellij pipe failed")
+	require.Equal(t, 3, calls)
+}
+
+func TestRetry_PermanentErrorIsNotRetried(t *testing.T) {
+	cause := errors.New("session not found")
+
+	var calls int
+	handler := Retry(fastRetryPolicy(3))(func(ctx context.Context, event Event) error {
+		calls++
+		return Permanent(cause)
+	})
+
+	err := handler(context.Background(), Event{Type: "test.event"})
+	require.ErrorIs(t, err, cause)
+	require.NotErrorIs(t, err, ErrRetriesExhausted)
+	require.Equal(t, 1, calls)
+}
+
+func TestRetry_StopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls int
+	policy := RetryPolicy{Attempts: 3, InitialBackoff: time.Hour}
+	handler := Retry(policy)(flakyHandler(5, &calls))
+
+	err := handler(ctx, Event{Type: "test.event"})
+	require.ErrorIs(t, err, ErrRetriesExhausted)
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+		Jitter:         0.5,
+	}
+
+	tests := map[string]struct {
+		retry    int
+		random   float64
+		expected time.Duration
+	}{
+		"first retry":    {retry: 1, random: 0.5, expected: 100 * time.Millisecond},
+		"exponential":    {retry: 3, random: 0.5, expected: 400 * time.Millisecond},
+		"capped":         {retry: 10, random: 0.5, expected: time.Second},
+		"jitter low":     {retry: 1, random: 0, expected: 50 * time.Millisecond},
+		"jitter high":    {retry: 2, random: 1, expected: 300 * time.Millisecond},
+		"capped jitters": {retry: 10, random: 0, expected: 500 * time.Millisecond},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, policy.Backoff(tt.retry, tt.random))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var ErrPayloadMismatch = errors.New("event payload has unexpected type")
+
+// Payload is an event body that names the event it is published as. Publish
+// and Subscribe take the event type from the payload, so a struct that isn't
+// an event payload doesn't compile.
+type Payload interface {
+	EventType() string
+}
+
+// TypedHandler receives an event's payload already asserted to T.
+type TypedHandler[T Payload] func(ctx context.Context, payload T) error
+
+// eventTypeOf returns the event carrying payload type T.
+func eventTypeOf[T Payload]() string {
+	var zero T
+	return zero.EventType()
+}
+
+// Publish sends payload as the event named by its type.
+func Publish[T Payload](ctx context.Context, bus EventBus, payload T) error {
+	return bus.Publish(ctx, Event{Type: payload.EventType(), Data: payload})
+}
+
+// Subscribe registers handler for the event carrying T, wrapped in mws. An
+// event whose payload isn't a T, e.g. one published through the untyped API,
+// fails the handler with ErrPayloadMismatch instead of being dropped.
+func Subscribe[T Payload](bus EventBus, handler TypedHandler[T], mws ...Middleware) Subscription {
+	return bus.Subscribe(eventTypeOf[T](), Chain(func(ctx context.Context, event Event) error {
+		payload, ok := event.Data.(T)
+		if !ok {
+			return fmt.Errorf("%w: %s carries %T, want %s", ErrPayloadMismatch, event.Type, event.Data, reflect.TypeFor[T]())
+		}
+		return handler(ctx, payload)
+	}, mws...))
+}
@@ -0,0 +1,80 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type typedTestEvent struct {
+	Value string
+}
+
+func (typedTestEvent) EventType() string { return "test.typed" }
+
+func TestTyped_PublishSubscribe(t *testing.T) {
+	bus := NewEventBus()
+
+	var received []string
+	Subscribe(bus, func(ctx context.Context, payload typedTestEvent) error {
+		received = append(received, payload.Value)
+		return nil
+	})
+
+	require.NoError(t, Publish(context.Background(), bus, typedTestEvent{Value: "hello"}))
+	require.Equal(t, []string{"hello"}, received)
+}
+
+func TestTyped_PublishUsesPayloadEventType(t *testing.T) {
+	bus := NewEventBus()
+
+	var received []string
+	bus.Subscribe(SessionCreated, func(ctx context.Context, event Event) error {
+		received = append(received, event.Type)
+		return nil
+	})
+
+	require.NoError(t, Publish(context.Background(), bus, SessionCreatedEvent{SessionID: "s1"}))
+	require.Equal(t, []string{SessionCreated}, received)
+}
+
+func TestTyped_PayloadMismatchIsReported(t *testing.T) {
+	bus := NewEventBus()
+
+	var called bool
+	Subscribe(bus, func(ctx context.Context, payload typedTestEvent) error {
+		called = true
+		return nil
+	})
+
+	// A pointer published through the untyped API doesn't match
+	err := bus.Publish(context.Background(), Event{Type: "test.typed", Data: &typedTestEvent{}})
+	require.ErrorIs(t, err, ErrPayloadMismatch)
+	require.False(t, called)
+}
+
+func TestTyped_EventTypesAreUnique(t *testing.T) {
+	payloads := []Payload{
+		SessionCreateRequestedEvent{},
+		SessionActivateRequestedEvent{},
+		SessionCreatedEvent{},
+		SessionStateChangedEvent{},
+		SessionAttachedEvent{},
+		SessionDetachedEvent{},
+		SessionDiedEvent{},
+		SessionWorkspaceChangedEvent{},
+		SessionDeletedEvent{},
+		WorkspaceAddedEvent{},
+		WorkspaceUpdatedEvent{},
+		WorkspaceRemovedEvent{},
+		ZellijConnectivityChangedEvent{},
+	}
+
+	seen := map[string]Payload{}
+	for _, payload := range payloads {
+		existing, ok := seen[payload.EventType()]
+		require.False(t, ok, "%T and %T both carry %s", existing, payload, payload.EventType())
+		seen[payload.EventType()] = payload
+	}
+}
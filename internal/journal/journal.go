@@ -0,0 +1,201 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/config"
+)
+
+// maxEntrySize bounds a single journal line when reading.
+const maxEntrySize = 1 << 20
+
+// Entry is one recorded event.
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	// CorrelationID is the ID of the HTTP request that caused the event, if
+	// any.
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	SessionID     string          `json:"session_id,omitempty"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// Journal is an append-only JSONL file of entries. Once the file would grow
+// past maxBytes it is rotated to path.1, path.1 to path.2 and so on, keeping
+// at most maxFiles rotated files.
+type Journal struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	file *os.File
+	size int64
+}
+
+func NewJournal(path string, maxBytes int64, maxFiles int) *Journal {
+	return &Journal{
+		path:     path,
+		maxBytes: maxBytes,
+		maxFiles: maxFiles,
+	}
+}
+
+func (j *Journal) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.open(); err != nil {
+		return err
+	}
+
+	if j.size > 0 && j.size+int64(len(line)) > j.maxBytes {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+
+	return nil
+}
+
+// Read calls fn for every entry, oldest first, across rotated files.
+// Unreadable lines are skipped so one torn write doesn't hide the history.
+func (j *Journal) Read(fn func(Entry) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	path, err := config.ExpandPath(j.path)
+	if err != nil {
+		return err
+	}
+
+	for i := j.maxFiles; i >= 0; i-- {
+		if err := readFile(rotatedPath(path, i), fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func (j *Journal) open() error {
+	if j.file != nil {
+		return nil
+	}
+
+	path, err := config.ExpandPath(j.path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat journal: %w", err)
+	}
+
+	j.file = file
+	j.size = info.Size()
+	return nil
+}
+
+func (j *Journal) rotate() error {
+	path := j.file.Name()
+	if err := j.file.Close(); err != nil {
+		return fmt.Errorf("failed to close journal for rotation: %w", err)
+	}
+	j.file = nil
+
+	if err := os.Remove(rotatedPath(path, j.maxFiles)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to drop oldest journal: %w", err)
+	}
+
+	for i := j.maxFiles - 1; i >= 0; i-- {
+		err := os.Rename(rotatedPath(path, i), rotatedPath(path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to rotate journal: %w", err)
+		}
+	}
+
+	return j.open()
+}
+
+func rotatedPath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+func readFile(path string, fn func(Entry) error) error {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			log.Printf("Skipping unreadable journal line in %s: %v", path, err)
+			continue
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+
+	return nil
+}
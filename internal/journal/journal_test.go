@@ -0,0 +1,117 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupJournal creates a journal in a temporary directory
+func setupJournal(t *testing.T, maxBytes int64, maxFiles int) (*Journal, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	journal := NewJournal(path, maxBytes, maxFiles)
+	t.Cleanup(func() {
+		journal.Close()
+	})
+
+	return journal, path
+}
+
+func readAll(t *testing.T, journal *Journal) []Entry {
+	t.Helper()
+
+	var entries []Entry
+	require.NoError(t, journal.Read(func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	return entries
+}
+
+func testEntry(i int) Entry {
+	return Entry{
+		Timestamp: time.Date(2026, 1, 27, 10, 0, i, 0, time.UTC),
+		Type:      "session.created",
+		SessionID: fmt.Sprintf("session-%d", i),
+		Data:      json.RawMessage(fmt.Sprintf(`{"session_id":"session-%d"}`, i)),
+	}
+}
+
+func TestJournal_AppendAndRead(t *testing.T) {
+	journal, _ := setupJournal(t, 1<<20, 2)
+
+	for i := range 3 {
+		require.NoError(t, journal.Append(testEntry(i)))
+	}
+
+	entries := readAll(t, journal)
+	require.Len(t, entries, 3)
+	require.Equal(t, "session-0", entries[0].SessionID)
+	require.Equal(t, "session-2", entries[2].SessionID)
+}
+
+func TestJournal_Read_Missing(t *testing.T) {
+	journal, _ := setupJournal(t, 1<<20, 2)
+	require.Empty(t, readAll(t, journal))
+}
+
+func TestJournal_Rotate(t *testing.T) {
+	line, err := json.Marshal(testEntry(0))
+	require.NoError(t, err)
+
+	// Two entries fit per file
+	journal, path := setupJournal(t, int64(2*(len(line)+1)), 2)
+
+	for i := range 7 {
+		require.NoError(t, journal.Append(testEntry(i)))
+	}
+
+	require.FileExists(t, path)
+	require.FileExists(t, path+".1")
+	require.FileExists(t, path+".2")
+	require.NoFileExists(t, path+".3")
+
+	// The oldest file was dropped; the rest read back in order
+	entries := readAll(t, journal)
+	require.Len(t, entries, 5)
+	for i, entry := range entries {
+		require.Equal(t, fmt.Sprintf("session-%d", i+2), entry.SessionID)
+	}
+}
+
+func TestJournal_Reopen(t *testing.T) {
+	journal, path := setupJournal(t, 1<<20, 2)
+	require.NoError(t, journal.Append(testEntry(0)))
+	require.NoError(t, journal.Close())
+
+	reopened := NewJournal(path, 1<<20, 2)
+	t.Cleanup(func() {
+		reopened.Close()
+	})
+	require.NoError(t, reopened.Append(testEntry(1)))
+
+	require.Len(t, readAll(t, reopened), 2)
+}
+
+func TestJournal_Read_SkipsCorruptLines(t *testing.T) {
+	journal, path := setupJournal(t, 1<<20, 2)
+	require.NoError(t, journal.Append(testEntry(0)))
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	require.NoError(t, err)
+	_, err = file.WriteString("{\"timestamp\": \"trunc\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	require.NoError(t, journal.Append(testEntry(1)))
+
+	entries := readAll(t, journal)
+	require.Len(t, entries, 2)
+}
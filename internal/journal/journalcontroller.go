@@ -0,0 +1,38 @@
+package journal
+
+import (
+	"net/http"
+
+	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/go-chi/render"
+)
+
+type JournalController struct {
+	service *JournalService
+}
+
+func NewJournalController(service *JournalService) *JournalController {
+	return &JournalController{
+		service: service,
+	}
+}
+
+func (c *JournalController) ListHistory(w http.ResponseWriter, r *http.Request) {
+	query, err := ParseHistoryQuery(r)
+	if err != nil {
+		render.Render(w, r, common.ErrInvalidRequest(err))
+		return
+	}
+
+	entries, err := c.service.History(query)
+	if err != nil {
+		render.Render(w, r, common.ErrUnknown(err))
+		return
+	}
+
+	render.Render(w, r, NewHistoryResponse(entries))
+}
+
+func (c *JournalController) GetUsage(w http.ResponseWriter, r *http.Request) {
+	render.Render(w, r, NewUsageResponse(c.service.Usage()))
+}
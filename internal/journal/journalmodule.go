@@ -0,0 +1,52 @@
+package journal
+
+import (
+	"context"
+
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/go-chi/chi/v5"
+)
+
+type JournalModule struct {
+	Journal    *Journal
+	Service    *JournalService
+	Controller *JournalController
+	Router     *JournalRouter
+}
+
+func NewJournalModule(cfg *config.Config, bus eventbus.EventBus) *JournalModule {
+	journal := NewJournal(cfg.JournalPath, cfg.JournalMaxBytes, cfg.JournalMaxFiles)
+	service := NewJournalService(journal, bus)
+	controller := NewJournalController(service)
+	router := NewJournalRouter(controller)
+
+	return &JournalModule{
+		Journal:    journal,
+		Service:    service,
+		Controller: controller,
+		Router:     router,
+	}
+}
+
+func (m *JournalModule) OnAppStart(ctx context.Context) error {
+
+	if err := m.Service.OnAppStart(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *JournalModule) OnAppEnd(ctx context.Context) error {
+
+	if err := m.Service.OnAppEnd(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *JournalModule) Routes() chi.Router {
+	return m.Router.Routes()
+}
@@ -0,0 +1,24 @@
+package journal
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+type JournalRouter struct {
+	controller *JournalController
+}
+
+func NewJournalRouter(controller *JournalController) *JournalRouter {
+	return &JournalRouter{
+		controller: controller,
+	}
+}
+
+func (jr *JournalRouter) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/history", jr.controller.ListHistory)
+	r.Get("/usage", jr.controller.GetUsage)
+
+	return r
+}
@@ -0,0 +1,254 @@
+package journal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/eleonorayaya/utena/internal/session"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// HistoryQuery filters journal entries. Zero values match everything.
+type HistoryQuery struct {
+	// Types are event type prefixes, e.g. "session." or "session.died".
+	Types     []string
+	SessionID string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (q HistoryQuery) matches(entry Entry) bool {
+	if q.SessionID != "" && entry.SessionID != q.SessionID {
+		return false
+	}
+
+	if !q.Since.IsZero() && entry.Timestamp.Before(q.Since) {
+		return false
+	}
+
+	if !q.Until.IsZero() && !entry.Timestamp.Before(q.Until) {
+		return false
+	}
+
+	if len(q.Types) == 0 {
+		return true
+	}
+
+	for _, prefix := range q.Types {
+		if strings.HasPrefix(entry.Type, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionUsage is derived from the journal, so it survives restarts even
+// though sessions themselves may not.
+type SessionUsage struct {
+	SessionID   string        `json:"session_id"`
+	CreatedAt   time.Time     `json:"created_at,omitzero"`
+	DiedAt      time.Time     `json:"died_at,omitzero"`
+	AttachCount int           `json:"attach_count"`
+	AttachedFor time.Duration `json:"-"`
+	// AttachedSeconds mirrors AttachedFor for API clients.
+	AttachedSeconds int64     `json:"attached_seconds"`
+	LastAttachedAt  time.Time `json:"last_attached_at,omitzero"`
+
+	attachedSince time.Time
+}
+
+type JournalService struct {
+	journal  *Journal
+	eventBus eventbus.EventBus
+	now      func() time.Time
+
+	sub eventbus.Subscription
+
+	mu    sync.Mutex
+	usage map[string]*SessionUsage
+}
+
+func NewJournalService(journal *Journal, bus eventbus.EventBus) *JournalService {
+	return &JournalService{
+		journal:  journal,
+		eventBus: bus,
+		now:      time.Now,
+		usage:    make(map[string]*SessionUsage),
+	}
+}
+
+// OnAppStart rebuilds usage statistics from the journal before recording
+// new events, so startup events aren't counted twice.
+func (s *JournalService) OnAppStart(ctx context.Context) error {
+	if err := s.journal.Read(func(entry Entry) error {
+		s.apply(entry)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to replay journal: %w", err)
+	}
+
+	s.sub = s.eventBus.Subscribe(eventbus.Wildcard, s.record)
+	return nil
+}
+
+func (s *JournalService) OnAppEnd(ctx context.Context) error {
+	if s.sub != nil {
+		s.sub.Unsubscribe()
+	}
+
+	return s.journal.Close()
+}
+
+// History returns the newest entries matching the query, oldest first.
+func (s *JournalService) History(query HistoryQuery) ([]Entry, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	limit = min(limit, maxHistoryLimit)
+
+	entries := make([]Entry, 0, limit)
+	err := s.journal.Read(func(entry Entry) error {
+		if !query.matches(entry) {
+			return nil
+		}
+
+		if len(entries) == limit {
+			entries = append(entries[1:], entry)
+		} else {
+			entries = append(entries, entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Usage returns per-session statistics, most recently attached first.
+func (s *JournalService) Usage() []SessionUsage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	usage := make([]SessionUsage, 0, len(s.usage))
+	for _, u := range s.usage {
+		stats := *u
+		if !stats.attachedSince.IsZero() {
+			stats.AttachedFor += now.Sub(stats.attachedSince)
+		}
+		stats.AttachedSeconds = int64(stats.AttachedFor / time.Second)
+		usage = append(usage, stats)
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		if !usage[i].LastAttachedAt.Equal(usage[j].LastAttachedAt) {
+			return usage[i].LastAttachedAt.After(usage[j].LastAttachedAt)
+		}
+		return usage[i].SessionID < usage[j].SessionID
+	})
+
+	return usage
+}
+
+func (s *JournalService) record(ctx context.Context, event eventbus.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s for journal: %w", event.Type, err)
+	}
+
+	// Async subscribers may handle the event well after it was published
+	timestamp := event.Time
+	if timestamp.IsZero() {
+		timestamp = s.now()
+	}
+
+	entry := Entry{
+		Timestamp:     timestamp,
+		Type:          event.Type,
+		CorrelationID: middleware.GetReqID(ctx),
+		SessionID:     sessionID(data),
+		Data:          data,
+	}
+
+	if err := s.journal.Append(entry); err != nil {
+		return err
+	}
+
+	s.apply(entry)
+	return nil
+}
+
+// apply folds one entry into the usage statistics. It is used both for
+// replay and for live events.
+func (s *JournalService) apply(entry Entry) {
+	if entry.SessionID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage, ok := s.usage[entry.SessionID]
+	if !ok {
+		usage = &SessionUsage{SessionID: entry.SessionID}
+		s.usage[entry.SessionID] = usage
+	}
+
+	switch entry.Type {
+	case eventbus.SessionCreated:
+		usage.CreatedAt = entry.Timestamp
+		usage.DiedAt = time.Time{}
+	case eventbus.SessionDied:
+		usage.DiedAt = entry.Timestamp
+	case eventbus.SessionStateChanged:
+		var change eventbus.SessionStateChangedEvent
+		if err := json.Unmarshal(entry.Data, &change); err != nil {
+			log.Printf("Skipping malformed %s in journal: %v", entry.Type, err)
+			return
+		}
+
+		if change.From == string(session.StateAttached) && !usage.attachedSince.IsZero() {
+			usage.AttachedFor += entry.Timestamp.Sub(usage.attachedSince)
+			usage.attachedSince = time.Time{}
+		}
+
+		if change.To == string(session.StateAttached) {
+			usage.AttachCount++
+			usage.LastAttachedAt = entry.Timestamp
+			usage.attachedSince = entry.Timestamp
+		}
+	}
+}
+
+// sessionID pulls the session a payload refers to, so history can be
+// filtered without knowing every payload type.
+func sessionID(data json.RawMessage) string {
+	var ref struct {
+		SessionID   string `json:"session_id"`
+		SessionName string `json:"session_name"`
+	}
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return ""
+	}
+
+	if ref.SessionID != "" {
+		return ref.SessionID
+	}
+	return ref.SessionName
+}
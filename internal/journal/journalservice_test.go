@@ -0,0 +1,193 @@
+package journal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable time source for usage statistics
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// clockedBus stamps published events from the fake clock, as the real buses
+// do from the wall clock
+type clockedBus struct {
+	eventbus.EventBus
+	clock *fakeClock
+}
+
+func (b clockedBus) Publish(ctx context.Context, event eventbus.Event) error {
+	if event.Time.IsZero() {
+		event.Time = b.clock.now
+	}
+	return b.EventBus.Publish(ctx, event)
+}
+
+// setupJournalService creates a started service journaling to path
+func setupJournalService(t *testing.T, path string) (*JournalService, eventbus.EventBus, *fakeClock) {
+	t.Helper()
+
+	clock := &fakeClock{now: time.Date(2026, 1, 27, 10, 0, 0, 0, time.UTC)}
+	bus := clockedBus{EventBus: eventbus.NewEventBus(), clock: clock}
+
+	service := NewJournalService(NewJournal(path, 1<<20, 2), bus)
+	service.now = clock.Now
+	require.NoError(t, service.OnAppStart(context.Background()))
+	t.Cleanup(func() {
+		service.OnAppEnd(context.Background())
+	})
+
+	return service, bus, clock
+}
+
+func changeState(t *testing.T, bus eventbus.EventBus, id, from, to string) {
+	t.Helper()
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.SessionStateChangedEvent{
+		SessionID: id,
+		From:      from,
+		To:        to,
+	}))
+}
+
+func TestJournalService_RecordsEvents(t *testing.T) {
+	service, bus, _ := setupJournalService(t, filepath.Join(t.TempDir(), "events.jsonl"))
+
+	ctx := context.WithValue(context.Background(), middleware.RequestIDKey, "req-1")
+	require.NoError(t, eventbus.Publish(ctx, bus, eventbus.SessionCreatedEvent{SessionID: "session-1", WorkspaceID: "ws-1"}))
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.WorkspaceAddedEvent{WorkspaceID: "ws-2"}))
+
+	entries, err := service.History(HistoryQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	require.Equal(t, eventbus.SessionCreated, entries[0].Type)
+	require.Equal(t, "session-1", entries[0].SessionID)
+	require.Equal(t, "req-1", entries[0].CorrelationID)
+	require.JSONEq(t, `{"session_id":"session-1","workspace_id":"ws-1","state":""}`, string(entries[0].Data))
+
+	require.Equal(t, eventbus.WorkspaceAdded, entries[1].Type)
+	require.Empty(t, entries[1].SessionID)
+	require.Empty(t, entries[1].CorrelationID)
+}
+
+func TestJournalService_StampsPublishTime(t *testing.T) {
+	service, bus, clock := setupJournalService(t, filepath.Join(t.TempDir(), "events.jsonl"))
+
+	// The event sat in a queue for a minute before the journal got to it
+	published := clock.now.Add(-time.Minute)
+	require.NoError(t, bus.Publish(context.Background(), eventbus.Event{
+		Type: eventbus.SessionDied,
+		Data: eventbus.SessionDiedEvent{SessionID: "session-1"},
+		Time: published,
+	}))
+
+	entries, err := service.History(HistoryQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, published, entries[0].Timestamp)
+}
+
+func TestJournalService_History_Filters(t *testing.T) {
+	service, bus, clock := setupJournalService(t, filepath.Join(t.TempDir(), "events.jsonl"))
+	start := clock.now
+
+	changeState(t, bus, "session-1", "running_detached", "attached")
+	clock.Advance(time.Hour)
+	changeState(t, bus, "session-2", "running_detached", "attached")
+	clock.Advance(time.Hour)
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.SessionDiedEvent{SessionID: "session-1"}))
+
+	tests := map[string]struct {
+		query    HistoryQuery
+		expected []string
+	}{
+		"type prefix": {
+			query:    HistoryQuery{Types: []string{"session.died"}},
+			expected: []string{"session-1"},
+		},
+		"session": {
+			query:    HistoryQuery{SessionID: "session-1"},
+			expected: []string{"session-1", "session-1"},
+		},
+		"time range": {
+			query:    HistoryQuery{Since: start.Add(time.Hour), Until: start.Add(2 * time.Hour)},
+			expected: []string{"session-2"},
+		},
+		"limit keeps newest": {
+			query:    HistoryQuery{Limit: 2},
+			expected: []string{"session-2", "session-1"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			entries, err := service.History(tt.query)
+			require.NoError(t, err)
+
+			ids := make([]string, len(entries))
+			for i, entry := range entries {
+				ids[i] = entry.SessionID
+			}
+			require.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestJournalService_Usage(t *testing.T) {
+	service, bus, clock := setupJournalService(t, filepath.Join(t.TempDir(), "events.jsonl"))
+
+	changeState(t, bus, "session-1", "running_detached", "attached")
+	clock.Advance(10 * time.Minute)
+	changeState(t, bus, "session-1", "attached", "running_detached")
+	changeState(t, bus, "session-2", "running_detached", "attached")
+	clock.Advance(5 * time.Minute)
+
+	usage := service.Usage()
+	require.Len(t, usage, 2)
+
+	// session-2 is still attached, so its open span counts up to now
+	require.Equal(t, "session-2", usage[0].SessionID)
+	require.Equal(t, 1, usage[0].AttachCount)
+	require.Equal(t, 5*time.Minute, usage[0].AttachedFor)
+
+	require.Equal(t, "session-1", usage[1].SessionID)
+	require.Equal(t, 10*time.Minute, usage[1].AttachedFor)
+	require.Equal(t, int64(600), usage[1].AttachedSeconds)
+}
+
+func TestJournalService_ReplayRebuildsUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	service, bus, clock := setupJournalService(t, path)
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.SessionCreatedEvent{SessionID: "session-1"}))
+	changeState(t, bus, "session-1", "running_detached", "attached")
+	clock.Advance(time.Hour)
+	changeState(t, bus, "session-1", "attached", "exited")
+	require.NoError(t, eventbus.Publish(context.Background(), bus, eventbus.SessionDiedEvent{SessionID: "session-1"}))
+	require.NoError(t, service.OnAppEnd(context.Background()))
+
+	restarted, _, _ := setupJournalService(t, path)
+
+	usage := restarted.Usage()
+	require.Len(t, usage, 1)
+	require.Equal(t, "session-1", usage[0].SessionID)
+	require.Equal(t, 1, usage[0].AttachCount)
+	require.Equal(t, time.Hour, usage[0].AttachedFor)
+	require.False(t, usage[0].CreatedAt.IsZero())
+	require.Equal(t, clock.now, usage[0].DiedAt)
+}
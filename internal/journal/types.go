@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type HistoryResponse struct {
+	Entries []Entry `json:"entries"`
+}
+
+func NewHistoryResponse(entries []Entry) *HistoryResponse {
+	return &HistoryResponse{Entries: entries}
+}
+
+func (hr *HistoryResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+type UsageResponse struct {
+	Sessions []SessionUsage `json:"sessions"`
+}
+
+func NewUsageResponse(usage []SessionUsage) *UsageResponse {
+	return &UsageResponse{Sessions: usage}
+}
+
+func (ur *UsageResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+// ParseHistoryQuery reads ?type= (repeatable or comma-separated prefixes),
+// ?session_id=, ?since=, ?until= (RFC 3339) and ?limit=.
+func ParseHistoryQuery(r *http.Request) (HistoryQuery, error) {
+	values := r.URL.Query()
+	query := HistoryQuery{SessionID: values.Get("session_id")}
+
+	for _, value := range values["type"] {
+		for _, prefix := range strings.Split(value, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				query.Types = append(query.Types, prefix)
+			}
+		}
+	}
+
+	var err error
+	if query.Since, err = parseTime(values.Get("since")); err != nil {
+		return query, fmt.Errorf("invalid since: %w", err)
+	}
+
+	if query.Until, err = parseTime(values.Get("until")); err != nil {
+		return query, fmt.Errorf("invalid until: %w", err)
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			return query, fmt.Errorf("invalid limit %q", limit)
+		}
+	}
+
+	return query, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,233 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/workspace"
+)
+
+const (
+	maxNameSuggestions = 3
+	fallbackBaseName   = "session"
+)
+
+var ErrInvalidSessionName = errors.New("invalid session name")
+
+// DuplicateSessionNameError is returned when a requested name is taken. It
+// carries free alternatives the client can offer instead.
+type DuplicateSessionNameError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *DuplicateSessionNameError) Error() string {
+	return fmt.Sprintf("session '%s' already exists", e.Name)
+}
+
+func (e *DuplicateSessionNameError) Unwrap() error {
+	return ErrSessionExists
+}
+
+// SessionNamer validates user supplied session names and generates defaults
+// when none is given, following the naming rules from the picker spec.
+type SessionNamer struct {
+	template  *template.Template
+	charset   string
+	namePat   *regexp.Regexp
+	charPat   *regexp.Regexp
+	maxLength int
+	style     string
+	now       func() time.Time
+}
+
+// NewSessionNamer builds a namer from validated config.
+func NewSessionNamer(cfg *config.Config) *SessionNamer {
+	namer := &SessionNamer{
+		charset:   cfg.SessionNameCharset,
+		namePat:   regexp.MustCompile("^[" + cfg.SessionNameCharset + "]+$"),
+		charPat:   regexp.MustCompile("^[" + cfg.SessionNameCharset + "]$"),
+		maxLength: cfg.SessionNameMaxLength,
+		style:     cfg.SessionNameStyle,
+		now:       time.Now,
+	}
+
+	if cfg.SessionNameTemplate != "" {
+		namer.template = template.Must(template.New("session_name").Parse(cfg.SessionNameTemplate))
+	}
+
+	return namer
+}
+
+func (n *SessionNamer) Validate(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidSessionName)
+	}
+
+	if len(name) > n.maxLength {
+		return fmt.Errorf("%w: name is longer than %d characters", ErrInvalidSessionName, n.maxLength)
+	}
+
+	if !n.namePat.MatchString(name) {
+		return fmt.Errorf("%w: name may only contain [%s]", ErrInvalidSessionName, n.charset)
+	}
+
+	return nil
+}
+
+// CheckAvailable returns a DuplicateSessionNameError when name is taken.
+func (n *SessionNamer) CheckAvailable(name string, exists func(string) bool) error {
+	if !exists(name) {
+		return nil
+	}
+
+	return &DuplicateSessionNameError{
+		Name:        name,
+		Suggestions: n.Suggest(name, exists),
+	}
+}
+
+// Suggest returns free alternatives of the form name-2, name-3, ...
+func (n *SessionNamer) Suggest(name string, exists func(string) bool) []string {
+	suggestions := make([]string, 0, maxNameSuggestions)
+	for counter := 2; len(suggestions) < maxNameSuggestions; counter++ {
+		candidate := n.withSuffix(name, strconv.Itoa(counter))
+		if !exists(candidate) {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return suggestions
+}
+
+// NameTemplateData holds the variables available to session_name_template.
+type NameTemplateData struct {
+	Workspace   string
+	WorkspaceID string
+	Branch      string
+	Date        string
+	Time        time.Time
+	Counter     int
+}
+
+// DefaultName generates a free name for a new session in the given workspace,
+// using the configured template or, without one, e.g. utena-1 or
+// utena-20260127103000 depending on the configured style.
+func (n *SessionNamer) DefaultName(ws *workspace.Workspace, exists func(string) bool) string {
+	if n.template != nil {
+		name, err := n.templateName(ws, exists)
+		if err == nil {
+			return name
+		}
+		log.Printf("session name template failed, using %s names: %v", n.style, err)
+	}
+
+	base := n.Sanitize(ws.Name)
+
+	if n.style == config.SessionNameTimestamp {
+		return n.freeName(n.withSuffix(base, n.now().Format("20060102150405")), exists)
+	}
+
+	for counter := 1; ; counter++ {
+		name := n.withSuffix(base, strconv.Itoa(counter))
+		if !exists(name) {
+			return name
+		}
+	}
+}
+
+// templateName renders the template with increasing counters until the name
+// is free. Templates that don't use the counter get a numeric suffix instead.
+func (n *SessionNamer) templateName(ws *workspace.Workspace, exists func(string) bool) (string, error) {
+	now := n.now()
+	data := NameTemplateData{
+		Workspace:   ws.Name,
+		WorkspaceID: ws.ID,
+		Branch:      workspace.CurrentBranch(ws.Path),
+		Date:        now.Format("2006-01-02"),
+		Time:        now,
+	}
+
+	var first string
+	for counter := 1; ; counter++ {
+		data.Counter = counter
+		name, err := n.render(data)
+		if err != nil {
+			return "", err
+		}
+
+		if !exists(name) {
+			return name, nil
+		}
+
+		if counter == 1 {
+			first = name
+		} else if name == first {
+			return n.freeName(first, exists), nil
+		}
+	}
+}
+
+func (n *SessionNamer) render(data NameTemplateData) (string, error) {
+	var b strings.Builder
+	if err := n.template.Execute(&b, data); err != nil {
+		return "", err
+	}
+
+	return n.Sanitize(b.String()), nil
+}
+
+func (n *SessionNamer) freeName(name string, exists func(string) bool) string {
+	if !exists(name) {
+		return name
+	}
+	return n.Suggest(name, exists)[0]
+}
+
+// Sanitize turns arbitrary text into a valid name by replacing disallowed
+// characters with dashes and trimming it to the maximum length.
+func (n *SessionNamer) Sanitize(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if n.charPat.MatchString(string(r)) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteByte('-')
+	}
+
+	name := collapseDashes(b.String())
+	name = strings.Trim(name, "-")
+	if len(name) > n.maxLength {
+		name = strings.TrimRight(name[:n.maxLength], "-")
+	}
+
+	if n.Validate(name) != nil {
+		return fallbackBaseName
+	}
+
+	return name
+}
+
+// withSuffix appends -suffix, shortening base so the result still fits.
+func (n *SessionNamer) withSuffix(base, suffix string) string {
+	suffix = "-" + suffix
+	if limit := n.maxLength - len(suffix); len(base) > limit && limit > 0 {
+		base = base[:limit]
+	}
+	return base + suffix
+}
+
+func collapseDashes(value string) string {
+	for strings.Contains(value, "--") {
+		value = strings.ReplaceAll(value, "--", "-")
+	}
+	return value
+}
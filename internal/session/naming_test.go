@@ -0,0 +1,203 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/workspace"
+	"github.com/stretchr/testify/require"
+)
+
+// setupNamer creates a namer with the default rules and a fixed clock
+func setupNamer(t *testing.T) *SessionNamer {
+	t.Helper()
+
+	namer := NewSessionNamer(config.Default())
+	namer.now = func() time.Time {
+		return time.Date(2026, 1, 27, 10, 30, 0, 0, time.UTC)
+	}
+	return namer
+}
+
+// existingNames returns an exists func backed by a fixed set of names
+func existingNames(names ...string) func(string) bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	return func(name string) bool { return set[name] }
+}
+
+func TestSessionNamer_Validate(t *testing.T) {
+	namer := setupNamer(t)
+
+	tests := []struct {
+		name        string
+		value       string
+		expectError bool
+	}{
+		{name: "alphanumeric", value: "utena1", expectError: false},
+		{name: "hyphen and underscore", value: "my-project_dev", expectError: false},
+		{name: "empty", value: "", expectError: true},
+		{name: "space", value: "my project", expectError: true},
+		{name: "slash", value: "my/project", expectError: true},
+		{name: "max length", value: strings.Repeat("a", 50), expectError: false},
+		{name: "too long", value: strings.Repeat("a", 51), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := namer.Validate(tt.value)
+			if tt.expectError {
+				require.ErrorIs(t, err, ErrInvalidSessionName)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSessionNamer_Validate_CustomRules(t *testing.T) {
+	cfg := config.Default()
+	cfg.SessionNameCharset = "a-z"
+	cfg.SessionNameMaxLength = 5
+	namer := NewSessionNamer(cfg)
+
+	require.NoError(t, namer.Validate("utena"))
+	require.ErrorIs(t, namer.Validate("Utena"), ErrInvalidSessionName)
+	require.ErrorIs(t, namer.Validate("utenas"), ErrInvalidSessionName)
+}
+
+func TestSessionNamer_CheckAvailable(t *testing.T) {
+	namer := setupNamer(t)
+
+	require.NoError(t, namer.CheckAvailable("utena", existingNames()))
+
+	err := namer.CheckAvailable("utena", existingNames("utena", "utena-3"))
+	var duplicate *DuplicateSessionNameError
+	require.ErrorAs(t, err, &duplicate)
+	require.Equal(t, "utena", duplicate.Name)
+	require.Equal(t, []string{"utena-2", "utena-4", "utena-5"}, duplicate.Suggestions)
+}
+
+func TestSessionNamer_Suggest_RespectsMaxLength(t *testing.T) {
+	namer := setupNamer(t)
+
+	name := strings.Repeat("a", 50)
+	for _, suggestion := range namer.Suggest(name, existingNames(name)) {
+		require.Len(t, suggestion, 50)
+		require.NoError(t, namer.Validate(suggestion))
+	}
+}
+
+func TestSessionNamer_DefaultName_Counter(t *testing.T) {
+	namer := setupNamer(t)
+	utena := &workspace.Workspace{ID: "ws-1", Name: "utena"}
+
+	require.Equal(t, "utena-1", namer.DefaultName(utena, existingNames()))
+	require.Equal(t, "utena-3", namer.DefaultName(utena, existingNames("utena-1", "utena-2")))
+}
+
+func TestSessionNamer_DefaultName_Timestamp(t *testing.T) {
+	cfg := config.Default()
+	cfg.SessionNameStyle = config.SessionNameTimestamp
+	namer := NewSessionNamer(cfg)
+	namer.now = func() time.Time {
+		return time.Date(2026, 1, 27, 10, 30, 0, 0, time.UTC)
+	}
+	utena := &workspace.Workspace{ID: "ws-1", Name: "utena"}
+
+	require.Equal(t, "utena-20260127103000", namer.DefaultName(utena, existingNames()))
+	require.Equal(t, "utena-20260127103000-2", namer.DefaultName(utena, existingNames("utena-20260127103000")))
+}
+
+// setupTemplateNamer creates a namer rendering the given template
+func setupTemplateNamer(t *testing.T, tmpl string) *SessionNamer {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.SessionNameTemplate = tmpl
+	require.NoError(t, cfg.Validate())
+
+	namer := NewSessionNamer(cfg)
+	namer.now = func() time.Time {
+		return time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	}
+	return namer
+}
+
+// setupBranchWorkspace creates a workspace whose repository is on branch
+func setupBranchWorkspace(t *testing.T, branch string) *workspace.Workspace {
+	t.Helper()
+
+	path := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(path, ".git"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(path, ".git", "HEAD"), []byte("ref: refs/heads/"+branch+"\n"), 0o644))
+
+	return &workspace.Workspace{ID: "ws-1", Name: "utena", Path: path, IsGitRepo: true}
+}
+
+func TestSessionNamer_DefaultName_Template(t *testing.T) {
+	ws := setupBranchWorkspace(t, "feature-x")
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{name: "workspace and branch", template: "{{.Workspace}}/{{.Branch}}", expected: "utena-feature-x"},
+		{name: "date", template: "api-{{.Date}}", expected: "api-2026-10-16"},
+		{name: "time format", template: `{{.Workspace}}-{{.Time.Format "0102"}}`, expected: "utena-1016"},
+		{name: "workspace id and counter", template: "{{.WorkspaceID}}-{{.Counter}}", expected: "ws-1-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			namer := setupTemplateNamer(t, tt.template)
+			require.Equal(t, tt.expected, namer.DefaultName(ws, existingNames()))
+		})
+	}
+}
+
+func TestSessionNamer_DefaultName_TemplateCounter(t *testing.T) {
+	ws := setupBranchWorkspace(t, "main")
+	namer := setupTemplateNamer(t, "{{.Workspace}}-{{.Branch}}-{{.Counter}}")
+
+	name := namer.DefaultName(ws, existingNames("utena-main-1", "utena-main-2"))
+	require.Equal(t, "utena-main-3", name)
+}
+
+func TestSessionNamer_DefaultName_TemplateWithoutCounter(t *testing.T) {
+	ws := setupBranchWorkspace(t, "main")
+	namer := setupTemplateNamer(t, "{{.Workspace}}-{{.Branch}}")
+
+	name := namer.DefaultName(ws, existingNames("utena-main"))
+	require.Equal(t, "utena-main-2", name)
+}
+
+func TestSessionNamer_DefaultName_TemplateFailsFallsBack(t *testing.T) {
+	ws := setupBranchWorkspace(t, "main")
+	namer := setupTemplateNamer(t, "{{.Unknown}}")
+
+	require.Equal(t, "utena-1", namer.DefaultName(ws, existingNames()))
+}
+
+func TestSessionNamer_DefaultName_TemplateNotARepo(t *testing.T) {
+	namer := setupTemplateNamer(t, "{{.Workspace}}/{{.Branch}}")
+	ws := &workspace.Workspace{ID: "ws-1", Name: "utena", Path: t.TempDir()}
+
+	require.Equal(t, "utena", namer.DefaultName(ws, existingNames()))
+}
+
+func TestSessionNamer_Sanitize(t *testing.T) {
+	namer := setupNamer(t)
+
+	require.Equal(t, "my-project", namer.Sanitize("my project"))
+	require.Equal(t, "site-example-com", namer.Sanitize("site.example.com"))
+	require.Equal(t, "session", namer.Sanitize("..."))
+	require.Len(t, namer.Sanitize(strings.Repeat("a", 80)), 50)
+}
@@ -1,12 +1,110 @@
 package session
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Session struct {
-	ID          string    `json:"id"`
-	WorkspaceID string    `json:"workspace_id"`
-	IsAttached  bool      `json:"is_attached"`
-	IsActive    bool      `json:"is_active"`
-	IsDead      bool      `json:"is_dead"`
-	LastUsedAt  time.Time `json:"last_used_at"`
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspace_id"`
+	State       State  `json:"state"`
+	// StateChangedAt records when the session last entered each state.
+	StateChangedAt map[State]time.Time `json:"state_changed_at,omitempty"`
+	LastUsedAt     time.Time           `json:"last_used_at"`
+}
+
+// IsAttached reports whether a client is currently attached to the session.
+func (s *Session) IsAttached() bool {
+	return s.State == StateAttached
+}
+
+// IsActive reports whether the session is running in Zellij.
+func (s *Session) IsActive() bool {
+	return s.State == StateAttached || s.State == StateRunningDetached
+}
+
+// IsDead reports whether the session has exited.
+func (s *Session) IsDead() bool {
+	return s.State == StateExited || s.State == StateArchived
+}
+
+// setState moves the session to state and records when it happened. It does
+// not check the transition table; callers go through SessionService for that.
+func (s *Session) setState(state State, at time.Time) {
+	if s.State == state {
+		return
+	}
+
+	stamps := make(map[State]time.Time, len(s.StateChangedAt)+1)
+	for k, v := range s.StateChangedAt {
+		stamps[k] = v
+	}
+	stamps[state] = at
+
+	s.State = state
+	s.StateChangedAt = stamps
+}
+
+// applyDefaultState gives sessions persisted before states existed, and
+// never confirmed by Zellij, the requested state.
+func (s *Session) applyDefaultState() {
+	if s.State == "" {
+		s.State = StateRequested
+	}
+}
+
+// sessionJSON carries the legacy booleans next to the state so older clients
+// keep working.
+type sessionJSON struct {
+	sessionFields
+	IsAttached bool `json:"is_attached"`
+	IsActive   bool `json:"is_active"`
+	IsDead     bool `json:"is_dead"`
+}
+
+// sessionFields has Session's fields without its methods, avoiding recursion
+// in MarshalJSON and UnmarshalJSON.
+type sessionFields Session
+
+func (s Session) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toJSON())
+}
+
+func (s Session) toJSON() sessionJSON {
+	return sessionJSON{
+		sessionFields: sessionFields(s),
+		IsAttached:    s.IsAttached(),
+		IsActive:      s.IsActive(),
+		IsDead:        s.IsDead(),
+	}
+}
+
+// UnmarshalJSON accepts both the state field and the legacy booleans. The
+// state wins when both are present.
+func (s *Session) UnmarshalJSON(data []byte) error {
+	var decoded sessionJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*s = Session(decoded.sessionFields)
+	if s.State == "" {
+		s.State = stateFromLegacy(decoded.IsAttached, decoded.IsActive, decoded.IsDead)
+	}
+
+	return nil
+}
+
+func stateFromLegacy(isAttached, isActive, isDead bool) State {
+	switch {
+	case isDead:
+		return StateExited
+	case isAttached:
+		return StateAttached
+	case isActive:
+		return StateRunningDetached
+	default:
+		return ""
+	}
 }
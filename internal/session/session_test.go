@@ -0,0 +1,80 @@
+package session
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_MarshalJSON_DerivesLegacyFields(t *testing.T) {
+	tests := []struct {
+		state      State
+		isAttached bool
+		isActive   bool
+		isDead     bool
+	}{
+		{state: StateRequested},
+		{state: StateStarting},
+		{state: StateRunningDetached, isActive: true},
+		{state: StateAttached, isAttached: true, isActive: true},
+		{state: StateExited, isDead: true},
+		{state: StateArchived, isDead: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.state), func(t *testing.T) {
+			data, err := json.Marshal(Session{ID: "session-1", WorkspaceID: "ws-1", State: tt.state})
+			require.NoError(t, err)
+
+			var decoded map[string]any
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			require.Equal(t, string(tt.state), decoded["state"])
+			require.Equal(t, tt.isAttached, decoded["is_attached"])
+			require.Equal(t, tt.isActive, decoded["is_active"])
+			require.Equal(t, tt.isDead, decoded["is_dead"])
+		})
+	}
+}
+
+func TestSession_UnmarshalJSON_Legacy(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected State
+	}{
+		{name: "attached", body: `{"id":"s","is_attached":true,"is_active":true}`, expected: StateAttached},
+		{name: "detached", body: `{"id":"s","is_active":true}`, expected: StateRunningDetached},
+		{name: "dead", body: `{"id":"s","is_attached":true,"is_dead":true}`, expected: StateExited},
+		{name: "no flags", body: `{"id":"s"}`, expected: ""},
+		{name: "state wins", body: `{"id":"s","state":"archived","is_active":true}`, expected: StateArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var session Session
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &session))
+			require.Equal(t, tt.expected, session.State)
+		})
+	}
+}
+
+func TestSession_JSONRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	session := Session{
+		ID:             "session-1",
+		WorkspaceID:    "ws-1",
+		State:          StateAttached,
+		StateChangedAt: map[State]time.Time{StateAttached: now},
+		LastUsedAt:     now,
+	}
+
+	data, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	var decoded Session
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, session.State, decoded.State)
+	require.True(t, decoded.StateChangedAt[StateAttached].Equal(now))
+}
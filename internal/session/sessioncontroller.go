@@ -1,9 +1,11 @@
 package session
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
@@ -68,12 +70,23 @@ func (c *SessionController) CreateSession(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := c.service.CreateSessionAndNotify(ctx, data.Session); err != nil {
-		render.Render(w, r, common.ErrUnknown(err))
+	creation, err := c.service.CreateSessionAndNotify(ctx, data.Session)
+	if err != nil {
+		var duplicate *DuplicateSessionNameError
+		switch {
+		case errors.As(err, &duplicate):
+			render.Render(w, r, NewDuplicateSessionNameResponse(duplicate))
+		case errors.Is(err, ErrSessionExists):
+			render.Render(w, r, common.ErrConflict(err))
+		case errors.Is(err, workspace.ErrWorkspaceNotFound), errors.Is(err, ErrWorkspacePathMissing), errors.Is(err, ErrInvalidSessionName):
+			render.Render(w, r, common.ErrInvalidRequest(err))
+		default:
+			render.Render(w, r, common.ErrUnknown(err))
+		}
 		return
 	}
 
-	response := NewSessionResponse(data.Session)
+	response := NewCreateSessionResponse(creation)
 	render.Status(r, http.StatusCreated)
 	render.Render(w, r, response)
 }
@@ -91,7 +104,14 @@ func (c *SessionController) UpdateSession(w http.ResponseWriter, r *http.Request
 	data.Session.ID = id
 
 	if err := c.service.UpdateSession(ctx, data.Session); err != nil {
-		render.Render(w, r, common.ErrUnknown(err))
+		switch {
+		case errors.Is(err, ErrSessionNotFound):
+			render.Render(w, r, common.ErrNotFound())
+		case errors.Is(err, ErrInvalidTransition):
+			render.Render(w, r, common.ErrConflict(err))
+		default:
+			render.Render(w, r, common.ErrUnknown(err))
+		}
 		return
 	}
 
@@ -99,6 +119,55 @@ func (c *SessionController) UpdateSession(w http.ResponseWriter, r *http.Request
 	render.Render(w, r, response)
 }
 
+func (c *SessionController) ActivateSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	activation, err := c.service.ActivateSession(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSessionNotFound):
+			render.Render(w, r, common.ErrNotFound())
+		case errors.Is(err, ErrSessionDead):
+			render.Render(w, r, common.ErrConflict(err))
+		default:
+			render.Render(w, r, common.ErrUnknown(err))
+		}
+		return
+	}
+
+	response := NewActivateSessionResponse(activation)
+	render.Render(w, r, response)
+}
+
+func (c *SessionController) ListTabs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	snapshot, err := c.service.GetSnapshot(ctx, id)
+	if err != nil {
+		render.Render(w, r, common.ErrNotFound())
+		return
+	}
+
+	response := NewTabListResponse(snapshot)
+	render.Render(w, r, response)
+}
+
+func (c *SessionController) ListPanes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	snapshot, err := c.service.GetSnapshot(ctx, id)
+	if err != nil {
+		render.Render(w, r, common.ErrNotFound())
+		return
+	}
+
+	response := NewPaneListResponse(snapshot)
+	render.Render(w, r, response)
+}
+
 func (c *SessionController) DeleteSession(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id := chi.URLParam(r, "id")
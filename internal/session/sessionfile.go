@@ -0,0 +1,133 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/storage"
+)
+
+// sessionFileVersion is bumped whenever the on-disk layout changes. Older
+// versions are migrated on load; newer versions are refused so a downgraded
+// daemon never overwrites data it doesn't understand.
+const sessionFileVersion = 1
+
+type sessionFileContents struct {
+	Version  int       `json:"version"`
+	SavedAt  time.Time `json:"saved_at"`
+	Sessions []Session `json:"sessions"`
+}
+
+// SessionFile reads and writes the session snapshot, ~/.config/utena/sessions.json
+// by default. A leading ~ in the path is expanded on use.
+type SessionFile struct {
+	path string
+}
+
+func NewSessionFile(path string) *SessionFile {
+	return &SessionFile{
+		path: path,
+	}
+}
+
+// Load returns the persisted sessions. A missing file yields no sessions. A
+// corrupt file is moved aside so the next save doesn't clobber it, and the
+// daemon starts with an empty registry.
+func (f *SessionFile) Load() ([]Session, error) {
+	path, err := config.ExpandPath(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Session{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	sessions, err := decodeSessionFile(data)
+	if errors.Is(err, errUnsupportedSessionFile) {
+		return nil, err
+	}
+	if err != nil {
+		quarantined, qErr := quarantineSessionFile(path)
+		if qErr != nil {
+			return nil, fmt.Errorf("session file %s is corrupt and could not be moved aside: %w", path, qErr)
+		}
+
+		log.Printf("Session file %s is corrupt (%v), moved to %s", path, err, quarantined)
+		return []Session{}, nil
+	}
+
+	return sessions, nil
+}
+
+// Save atomically replaces the session file.
+func (f *SessionFile) Save(sessions []Session) error {
+	path, err := config.ExpandPath(f.path)
+	if err != nil {
+		return err
+	}
+
+	contents := sessionFileContents{
+		Version:  sessionFileVersion,
+		SavedAt:  time.Now(),
+		Sessions: sessions,
+	}
+
+	data, err := json.MarshalIndent(contents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sessions: %w", err)
+	}
+
+	if err := storage.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+
+	return nil
+}
+
+func quarantineSessionFile(path string) (string, error) {
+	target := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102150405"))
+	if err := os.Rename(path, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+var errUnsupportedSessionFile = errors.New("session file was written by a newer version of utena")
+
+func decodeSessionFile(data []byte) ([]Session, error) {
+	var raw json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// Version 0 was a bare array of sessions, as described in the spec.
+	var legacy []Session
+	if err := json.Unmarshal(raw, &legacy); err == nil {
+		return legacy, nil
+	}
+
+	var contents sessionFileContents
+	if err := json.Unmarshal(raw, &contents); err != nil {
+		return nil, err
+	}
+
+	if contents.Version > sessionFileVersion {
+		return nil, fmt.Errorf("%w: version %d", errUnsupportedSessionFile, contents.Version)
+	}
+
+	if contents.Sessions == nil {
+		return []Session{}, nil
+	}
+
+	return contents.Sessions, nil
+}
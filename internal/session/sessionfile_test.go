@@ -0,0 +1,94 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupSessionFile(t *testing.T) (*SessionFile, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "utena", "sessions.json")
+	return NewSessionFile(path), path
+}
+
+func TestSessionFile_Load_Missing(t *testing.T) {
+	file, _ := setupSessionFile(t)
+
+	sessions, err := file.Load()
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestSessionFile_SaveAndLoad(t *testing.T) {
+	file, path := setupSessionFile(t)
+
+	lastUsed := time.Date(2026, 1, 27, 10, 30, 0, 0, time.UTC)
+	err := file.Save([]Session{
+		{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: lastUsed},
+	})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"version": 1`)
+
+	sessions, err := file.Load()
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "session-1", sessions[0].ID)
+	require.True(t, sessions[0].IsActive())
+	require.True(t, lastUsed.Equal(sessions[0].LastUsedAt))
+
+	// No temp files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestSessionFile_Load_LegacyArray(t *testing.T) {
+	file, path := setupSessionFile(t)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+
+	legacy := `[{"id": "session-1", "workspace_id": "ws-1", "last_used_at": "2026-01-27T10:30:00Z"}]`
+	require.NoError(t, os.WriteFile(path, []byte(legacy), 0o644))
+
+	sessions, err := file.Load()
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "session-1", sessions[0].ID)
+}
+
+func TestSessionFile_Load_Corrupt(t *testing.T) {
+	file, path := setupSessionFile(t)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "sessions": [{"id": "sess`), 0o644))
+
+	sessions, err := file.Load()
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	// The corrupt file is preserved next to the original
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.True(t, strings.HasPrefix(entries[0].Name(), "sessions.json.corrupt-"))
+}
+
+func TestSessionFile_Load_NewerVersion(t *testing.T) {
+	file, path := setupSessionFile(t)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "sessions": []}`), 0o644))
+
+	_, err := file.Load()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "newer version")
+
+	// The file is left untouched
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+}
@@ -0,0 +1,139 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/eleonorayaya/utena/internal/storage"
+)
+
+// KVSessionStore keeps sessions in an in-memory index for reads and writes
+// every mutation through to a key/value backend, one key per session.
+type KVSessionStore struct {
+	mu    sync.Mutex
+	cache *SessionStore
+	kv    storage.KV
+}
+
+func NewKVSessionStore(kv storage.KV) *KVSessionStore {
+	return &KVSessionStore{
+		cache: NewSessionStore(),
+		kv:    kv,
+	}
+}
+
+func (s *KVSessionStore) GetByID(id string) (*Session, error) {
+	return s.cache.GetByID(id)
+}
+
+func (s *KVSessionStore) List() []Session {
+	return s.cache.List()
+}
+
+func (s *KVSessionStore) ListByWorkspace(workspaceID string) []Session {
+	return s.cache.ListByWorkspace(workspaceID)
+}
+
+func (s *KVSessionStore) Add(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.cache.Add(session); err != nil {
+		return err
+	}
+
+	if err := s.put(session); err != nil {
+		s.cache.Delete(session.ID)
+		return err
+	}
+
+	return nil
+}
+
+func (s *KVSessionStore) Update(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rollback *Session
+	if session != nil {
+		if previous, err := s.cache.GetByID(session.ID); err == nil {
+			saved := *previous
+			rollback = &saved
+		}
+	}
+
+	if err := s.cache.Update(session); err != nil {
+		return err
+	}
+
+	if err := s.put(session); err != nil {
+		s.cache.Update(rollback)
+		return err
+	}
+
+	return nil
+}
+
+func (s *KVSessionStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.cache.GetByID(id)
+	if err != nil {
+		return s.cache.Delete(id)
+	}
+
+	if err := s.cache.Delete(id); err != nil {
+		return err
+	}
+
+	if err := s.kv.Delete(id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.cache.Add(previous)
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+func (s *KVSessionStore) OnAppStart(ctx context.Context) error {
+	values, err := s.kv.List()
+	if err != nil {
+		return err
+	}
+
+	for key, data := range values {
+		var session Session
+		if err := json.Unmarshal(data, &session); err != nil {
+			log.Printf("Skipping unreadable session %q: %v", key, err)
+			continue
+		}
+		session.applyDefaultState()
+
+		if err := s.cache.Add(&session); err != nil {
+			log.Printf("Skipping invalid session %q: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *KVSessionStore) OnAppEnd(ctx context.Context) error {
+	return nil
+}
+
+func (s *KVSessionStore) put(session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := s.kv.Put(session.ID, data); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func setupKVSessionStore(t *testing.T) (*KVSessionStore, storage.KV) {
+	t.Helper()
+	kv := storage.NewFileKV(filepath.Join(t.TempDir(), "sessions"))
+	return NewKVSessionStore(kv), kv
+}
+
+func TestKVSessionStore_ImplementsSessionStorage(t *testing.T) {
+	var _ SessionStorage = NewKVSessionStore(nil)
+	var _ SessionStorage = NewSessionStore()
+}
+
+func TestKVSessionStore_AddPersists(t *testing.T) {
+	store, kv := setupKVSessionStore(t)
+
+	session := &Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}
+	require.NoError(t, store.Add(session))
+
+	_, err := kv.Get("session-1")
+	require.NoError(t, err)
+
+	reloaded := NewKVSessionStore(kv)
+	require.NoError(t, reloaded.OnAppStart(context.Background()))
+
+	retrieved, err := reloaded.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", retrieved.WorkspaceID)
+}
+
+func TestKVSessionStore_Add_Duplicate(t *testing.T) {
+	store, _ := setupKVSessionStore(t)
+
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+
+	err := store.Add(&Session{ID: "session-1", WorkspaceID: "ws-2", LastUsedAt: time.Now()})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exists")
+}
+
+func TestKVSessionStore_Update(t *testing.T) {
+	store, kv := setupKVSessionStore(t)
+
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+	require.NoError(t, store.Update(&Session{ID: "session-1", WorkspaceID: "ws-2", LastUsedAt: time.Now()}))
+
+	reloaded := NewKVSessionStore(kv)
+	require.NoError(t, reloaded.OnAppStart(context.Background()))
+
+	retrieved, err := reloaded.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-2", retrieved.WorkspaceID)
+}
+
+func TestKVSessionStore_Update_NotFound(t *testing.T) {
+	store, _ := setupKVSessionStore(t)
+
+	err := store.Update(&Session{ID: "missing", WorkspaceID: "ws-1", LastUsedAt: time.Now()})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+}
+
+func TestKVSessionStore_Delete(t *testing.T) {
+	store, kv := setupKVSessionStore(t)
+
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+	require.NoError(t, store.Delete("session-1"))
+
+	_, err := kv.Get("session-1")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	err = store.Delete("session-1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+}
+
+func TestKVSessionStore_List(t *testing.T) {
+	store, _ := setupKVSessionStore(t)
+
+	now := time.Now()
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: now.Add(-time.Hour)}))
+	require.NoError(t, store.Add(&Session{ID: "session-2", WorkspaceID: "ws-2", LastUsedAt: now}))
+
+	list := store.List()
+	require.Len(t, list, 2)
+	require.Equal(t, "session-2", list[0].ID)
+
+	require.Len(t, store.ListByWorkspace("ws-1"), 1)
+}
@@ -3,21 +3,22 @@ package session
 import (
 	"context"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/go-chi/chi/v5"
 )
 
 type SessionModule struct {
-	Store      *SessionStore
+	Store      SessionStorage
 	Service    *SessionService
 	Controller *SessionController
 	Router     *SessionRouter
 }
 
-func NewSessionModule(workspaceModule *workspace.WorkspaceModule, bus eventbus.EventBus) *SessionModule {
-	store := NewSessionStore()
-	service := NewSessionService(store, workspaceModule.Store, bus)
+func NewSessionModule(cfg *config.Config, workspaceModule *workspace.WorkspaceModule, bus eventbus.EventBus) *SessionModule {
+	store := NewSessionStorage(cfg)
+	service := NewSessionService(store, workspaceModule.Store, NewSessionNamer(cfg), bus)
 	controller := NewSessionController(service)
 	router := NewSessionRouter(controller)
 
@@ -22,6 +22,9 @@ func (sr *SessionRouter) Routes() chi.Router {
 	r.Get("/{id}", sr.controller.GetSessionByID)
 	r.Put("/{id}", sr.controller.UpdateSession)
 	r.Delete("/{id}", sr.controller.DeleteSession)
+	r.Put("/{id}/activate", sr.controller.ActivateSession)
+	r.Get("/{id}/tabs", sr.controller.ListTabs)
+	r.Get("/{id}/panes", sr.controller.ListPanes)
 	r.Get("/workspace/{workspaceId}", sr.controller.ListSessionsByWorkspace)
 
 	return r
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/stretchr/testify/require"
@@ -23,11 +25,9 @@ func setupSessionRouter(t *testing.T) (*SessionRouter, *SessionStore, *workspace
 	workspaceStore := workspace.NewWorkspaceStore()
 
 	// Initialize workspace store with test data
-	ctx := context.Background()
-	err := workspaceStore.OnAppStart(ctx)
-	require.NoError(t, err)
+	seedWorkspaces(t, workspaceStore)
 
-	service := NewSessionService(sessionStore, workspaceStore, bus)
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), bus)
 	controller := NewSessionController(service)
 	router := NewSessionRouter(controller)
 
@@ -75,8 +75,7 @@ func TestSessionRouter_GetSessionByID(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		State:       StateAttached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(session)
@@ -96,8 +95,8 @@ func TestSessionRouter_GetSessionByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "session-1", response.ID)
 	require.Equal(t, "ws-1", response.WorkspaceID)
-	require.True(t, response.IsAttached)
-	require.True(t, response.IsActive)
+	require.True(t, response.IsAttached())
+	require.True(t, response.IsActive())
 }
 
 func TestSessionRouter_GetSessionByID_NotFound(t *testing.T) {
@@ -154,8 +153,7 @@ func TestSessionRouter_CreateSession(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		State:       StateAttached,
 		LastUsedAt:  time.Now(),
 	}
 	body, err := json.Marshal(session)
@@ -172,6 +170,12 @@ func TestSessionRouter_CreateSession(t *testing.T) {
 	// Assert
 	require.Equal(t, http.StatusCreated, w.Code)
 
+	var response CreateSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, "session-1", response.ID)
+	require.Equal(t, StateRequested, response.State)
+	require.NotEmpty(t, response.CommandID)
+
 	// Verify session was created
 	retrieved, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
@@ -210,14 +214,13 @@ func TestSessionRouter_UpdateSession(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    true,
+		State:       StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(session)
 
 	// Update session
-	session.IsAttached = true
+	session.State = StateAttached
 	body, err := json.Marshal(session)
 	require.NoError(t, err)
 
@@ -235,7 +238,7 @@ func TestSessionRouter_UpdateSession(t *testing.T) {
 	// Verify update
 	retrieved, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
-	require.True(t, retrieved.IsAttached)
+	require.True(t, retrieved.IsAttached())
 }
 
 func TestSessionRouter_DeleteSession(t *testing.T) {
@@ -259,3 +262,197 @@ func TestSessionRouter_DeleteSession(t *testing.T) {
 	_, err := sessionStore.GetByID("session-1")
 	require.Error(t, err)
 }
+
+func TestSessionRouter_ActivateSession(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: time.Now().Add(-1 * time.Hour)})
+
+	req := httptest.NewRequest("PUT", "/session-1/activate", nil)
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response ActivateSessionResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.True(t, response.Success)
+	require.NotEmpty(t, response.Message)
+	require.NotEmpty(t, response.CommandID)
+}
+
+func TestSessionRouter_ActivateSession_SendFails(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	router.controller.service.eventBus.Subscribe(eventbus.SessionActivateRequested, func(ctx context.Context, event eventbus.Event) error {
+		return errors.New("zellij pipe failed")
+	})
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: time.Now().Add(-1 * time.Hour)})
+
+	req := httptest.NewRequest("PUT", "/session-1/activate", nil)
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response ActivateSessionResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.False(t, response.Success)
+	require.Contains(t, response.Message, "zellij pipe failed")
+	require.NotEmpty(t, response.CommandID)
+}
+
+func TestSessionRouter_ActivateSession_NotFound(t *testing.T) {
+	router, _, _ := setupSessionRouter(t)
+
+	req := httptest.NewRequest("PUT", "/nonexistent/activate", nil)
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSessionRouter_ActivateSession_Dead(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateExited, LastUsedAt: time.Now()})
+
+	req := httptest.NewRequest("PUT", "/session-1/activate", nil)
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSessionRouter_CreateSession_MissingDirectory(t *testing.T) {
+	router, _, workspaceStore := setupSessionRouter(t)
+
+	workspaceStore.Add(&workspace.Workspace{ID: "ws-gone", Name: "deleted", Path: "/nonexistent/deleted"})
+
+	session := &Session{ID: "session-1", WorkspaceID: "ws-gone", LastUsedAt: time.Now()}
+	body, err := json.Marshal(session)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "workspace directory does not exist")
+}
+
+func TestSessionRouter_CreateSession_DefaultName(t *testing.T) {
+	router, _, _ := setupSessionRouter(t)
+
+	body := []byte(`{"workspace_id":"ws-1"}`)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var response Session
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, "utena-1", response.ID)
+}
+
+func TestSessionRouter_CreateSession_DuplicateName(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	sessionStore.Add(&Session{ID: "utena", WorkspaceID: "ws-1", LastUsedAt: time.Now()})
+
+	body := []byte(`{"id":"utena","workspace_id":"ws-1"}`)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	var response struct {
+		Error       string   `json:"error"`
+		Suggestions []string `json:"suggestions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Contains(t, response.Error, "already exists")
+	require.Equal(t, []string{"utena-2", "utena-3", "utena-4"}, response.Suggestions)
+}
+
+func TestSessionRouter_CreateSession_InvalidName(t *testing.T) {
+	router, _, _ := setupSessionRouter(t)
+
+	body := []byte(`{"id":"my/project","workspace_id":"ws-1"}`)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSessionRouter_UpdateSession_InvalidTransition(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+
+	body := []byte(`{"id":"session-1","workspace_id":"ws-1","state":"archived","last_used_at":"2026-01-27T10:30:00Z"}`)
+	req := httptest.NewRequest("PUT", "/session-1", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.Routes().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestSessionRouter_ListTabsAndPanes(t *testing.T) {
+	router, sessionStore, _ := setupSessionRouter(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+	err := router.controller.service.SetSnapshot(context.Background(), "session-1", &Snapshot{
+		Tabs: []Tab{{Position: 0, Name: "editor", Active: true}, {Position: 1, Name: "server"}},
+		Panes: []Pane{
+			{ID: 1, TabPosition: 0, Title: "nvim", Command: "nvim", IsFocused: true},
+			{ID: 2, TabPosition: 1, Title: "go run", Command: "go run ./cmd/daemon"},
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/session-1/tabs", nil)
+	w := httptest.NewRecorder()
+	router.Routes().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tabs TabListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tabs))
+	require.Len(t, tabs.Tabs, 2)
+	require.True(t, tabs.Tabs[0].Active)
+
+	req = httptest.NewRequest("GET", "/session-1/panes", nil)
+	w = httptest.NewRecorder()
+	router.Routes().ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var panes PaneListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &panes))
+	require.Len(t, panes.Panes, 2)
+	require.Equal(t, "go run ./cmd/daemon", panes.Panes[1].Command)
+
+	req = httptest.NewRequest("GET", "/missing/panes", nil)
+	w = httptest.NewRecorder()
+	router.Routes().ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
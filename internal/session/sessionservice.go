@@ -2,23 +2,43 @@ package session
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/common"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/workspace"
 )
 
+var (
+	ErrSessionDead          = errors.New("session is no longer running")
+	ErrWorkspacePathMissing = errors.New("workspace directory does not exist")
+)
+
 type SessionService struct {
-	store          *SessionStore
-	workspaceStore *workspace.WorkspaceStore
+	store          SessionStorage
+	workspaceStore workspace.WorkspaceStorage
+	namer          *SessionNamer
 	eventBus       eventbus.EventBus
+	snapshots      *SnapshotStore
+
+	// mu makes each read-check-write of a stored session atomic, so a change
+	// computed from a stale read can't skip checkTransition.
+	mu sync.Mutex
 }
 
-func NewSessionService(store *SessionStore, workspaceStore *workspace.WorkspaceStore, bus eventbus.EventBus) *SessionService {
+func NewSessionService(store SessionStorage, workspaceStore workspace.WorkspaceStorage, namer *SessionNamer, bus eventbus.EventBus) *SessionService {
 	return &SessionService{
 		store:          store,
 		workspaceStore: workspaceStore,
+		namer:          namer,
 		eventBus:       bus,
+		snapshots:      NewSnapshotStore(),
 	}
 }
 
@@ -51,37 +71,217 @@ func (s *SessionService) GetSession(ctx context.Context, id string) (*Session, e
 
 func (s *SessionService) CreateSession(ctx context.Context, session *Session) error {
 
-	_, err := s.workspaceStore.GetByID(session.WorkspaceID)
+	ws, err := s.workspaceStore.GetByID(session.WorkspaceID)
 	if err != nil {
 		return err
 	}
 
+	if session.ID == "" {
+		session.ID = s.namer.DefaultName(ws, s.sessionExists)
+	}
+
+	now := time.Now()
 	if session.LastUsedAt.IsZero() {
-		session.LastUsedAt = time.Now()
+		session.LastUsedAt = now
+	}
+
+	if err := ValidateState(session.State); err != nil {
+		return err
+	}
+	state := session.State
+	if state == "" {
+		state = StateRequested
 	}
+	session.State = ""
+	session.setState(state, now)
 
 	if err := s.store.Add(session); err != nil {
 		return err
 	}
 
+	publish(ctx, s.eventBus, eventbus.SessionCreatedEvent{
+		SessionID:   session.ID,
+		WorkspaceID: session.WorkspaceID,
+		State:       string(session.State),
+	})
+
 	return nil
 }
 
-func (s *SessionService) CreateSessionAndNotify(ctx context.Context, session *Session) error {
+// Creation is the result of asking Zellij to open a new session. The
+// session waits in requested until the plugin reports it; CommandID tracks
+// the create command.
+type Creation struct {
+	Session   *Session
+	CommandID string
+}
+
+// CreateSessionAndNotify stores a requested session and asks Zellij to open
+// it. A command that fails once it reached its handler is reported through
+// the command's status and the dead letters, where it can be re-driven, so the
+// session is kept.
+func (s *SessionService) CreateSessionAndNotify(ctx context.Context, session *Session) (*Creation, error) {
+	ws, err := s.resolveWorkspace(session.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.ID != "" {
+		if err := s.validateName(session.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Zellij hasn't seen the session yet, whatever the client claims
+	session.State = StateRequested
+
 	if err := s.CreateSession(ctx, session); err != nil {
+		// Another request may have taken the name since it was validated
+		if errors.Is(err, ErrSessionExists) {
+			return nil, &DuplicateSessionNameError{
+				Name:        session.ID,
+				Suggestions: s.namer.Suggest(session.ID, s.sessionExists),
+			}
+		}
+		return nil, err
+	}
+
+	event := eventbus.SessionCreateRequestedEvent{
+		CommandID:     common.NewID(),
+		SessionName:   session.ID,
+		WorkspaceID:   ws.ID,
+		WorkspaceName: ws.Name,
+		WorkspacePath: ws.Path,
+	}
+	err = eventbus.Publish(ctx, s.eventBus, event)
+	if dropped(err) {
+		// Nothing will ever send the command, so the session would wait in
+		// requested forever
+		if err := s.DeleteSession(ctx, session.ID); err != nil {
+			log.Printf("Failed to remove session %s after its create was dropped: %v", session.ID, err)
+		}
+		return nil, fmt.Errorf("failed to request session %s from zellij: %w", session.ID, err)
+	}
+	if err != nil {
+		log.Printf("Requesting session %s from zellij failed: %v", session.ID, err)
+	}
+
+	return &Creation{Session: session, CommandID: event.CommandID}, nil
+}
+
+// validateName enforces the naming rules on client supplied names. Sessions
+// discovered from Zellij bypass this since their names are already in use.
+func (s *SessionService) validateName(name string) error {
+	if err := s.namer.Validate(name); err != nil {
 		return err
 	}
 
-	event := eventbus.Event{
-		Type: eventbus.SessionCreateRequested,
-		Data: eventbus.SessionCreateRequestedEvent{
-			SessionName:   session.ID,
-			WorkspacePath: "",
-		},
+	return s.namer.CheckAvailable(name, s.sessionExists)
+}
+
+func (s *SessionService) sessionExists(id string) bool {
+	_, err := s.store.GetByID(id)
+	return err == nil
+}
+
+// dropped reports whether publishing a command failed before it was queued,
+// so nothing will carry it out or record its status. A full queue can't tell
+// which subscriber missed the event, so it's treated as the command's.
+func dropped(err error) bool {
+	return errors.Is(err, eventbus.ErrBusClosed) || errors.Is(err, eventbus.ErrQueueFull)
+}
+
+// resolveWorkspace returns the session's workspace with an absolute path that
+// still exists on disk, so Zellij never opens a session in a bogus cwd.
+func (s *SessionService) resolveWorkspace(workspaceID string) (*workspace.Workspace, error) {
+	ws, err := s.workspaceStore.GetByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ws.Path == "" {
+		return nil, fmt.Errorf("%w: workspace %s has no directory", ErrWorkspacePathMissing, ws.ID)
 	}
-	s.eventBus.Publish(ctx, event)
 
-	return nil
+	path, err := filepath.Abs(ws.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWorkspacePathMissing, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrWorkspacePathMissing, path)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s is not a directory", ErrWorkspacePathMissing, path)
+	}
+
+	resolved := *ws
+	resolved.Path = path
+	return &resolved, nil
+}
+
+// InferWorkspaceID maps a session's working directory to the most specific
+// workspace containing it, falling back to the unassigned workspace.
+func (s *SessionService) InferWorkspaceID(ctx context.Context, cwd string) string {
+	if ws, ok := workspace.MatchPath(s.workspaceStore.List(), cwd); ok {
+		return ws.ID
+	}
+
+	return workspace.UnassignedWorkspaceID
+}
+
+// Activation is the result of asking Zellij to switch to a session. The
+// switch itself happens in the plugin; CommandID tracks its outcome.
+type Activation struct {
+	Session   *Session
+	CommandID string
+	// Err is set when the switch failed before the plugin got it
+	Err error
+}
+
+// ActivateSession asks Zellij to switch to the session and marks it as most
+// recently used. Whether the plugin switched is reported through the
+// command's status; a send that failed on the way is also reported in Err,
+// and only a switch that couldn't be requested at all fails.
+func (s *SessionService) ActivateSession(ctx context.Context, id string) (*Activation, error) {
+	session, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.IsDead() {
+		return nil, ErrSessionDead
+	}
+
+	event := eventbus.SessionActivateRequestedEvent{
+		CommandID:   common.NewID(),
+		SessionName: session.ID,
+	}
+	err = eventbus.Publish(ctx, s.eventBus, event)
+	if dropped(err) {
+		return nil, fmt.Errorf("failed to switch to session %s: %w", session.ID, err)
+	}
+	if err != nil {
+		// The command's status and the dead letters carry the failure
+		log.Printf("Switching to session %s failed: %v", session.ID, err)
+		return &Activation{Session: session, CommandID: event.CommandID, Err: err}, nil
+	}
+
+	// Recency only moves once the switch is under way. Handlers may have
+	// changed the session meanwhile, so only LastUsedAt is written over the
+	// latest copy. The switch can't be taken back, so failing to store it is
+	// logged rather than returned.
+	_, activated, err := s.modify(session.ID, func(latest Session) (*Session, error) {
+		latest.LastUsedAt = time.Now()
+		return &latest, nil
+	})
+	if err != nil {
+		log.Printf("Failed to record activation of session %s: %v", session.ID, err)
+		return &Activation{Session: session, CommandID: event.CommandID}, nil
+	}
+
+	return &Activation{Session: activated, CommandID: event.CommandID}, nil
 }
 
 func (s *SessionService) UpdateSession(ctx context.Context, session *Session) error {
@@ -93,9 +293,172 @@ func (s *SessionService) UpdateSession(ctx context.Context, session *Session) er
 		}
 	}
 
-	return s.store.Update(session)
+	before, _, err := s.modify(session.ID, func(existing Session) (*Session, error) {
+		if err := ValidateState(session.State); err != nil {
+			return nil, err
+		}
+
+		// State history is owned by the service; only the target state is
+		// taken from the caller, and an empty state keeps the current one.
+		target := session.State
+		if target == "" {
+			target = existing.State
+		}
+		if err := checkTransition(existing.State, target); err != nil {
+			return nil, err
+		}
+
+		session.State = existing.State
+		session.StateChangedAt = existing.StateChangedAt
+		session.setState(target, time.Now())
+		return session, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.dropSnapshotIfDead(session)
+
+	s.publishChanges(ctx, before, session)
+	return nil
+}
+
+// TransitionSession moves a session to a new lifecycle state, rejecting moves
+// the transition table doesn't allow.
+func (s *SessionService) TransitionSession(ctx context.Context, id string, to State) (*Session, error) {
+	before, updated, err := s.modify(id, func(existing Session) (*Session, error) {
+		if err := checkTransition(existing.State, to); err != nil {
+			return nil, err
+		}
+
+		existing.setState(to, time.Now())
+		return &existing, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.dropSnapshotIfDead(updated)
+
+	s.publishChanges(ctx, before, updated)
+	return updated, nil
+}
+
+// modify stores change's result for the session under mu, so no other change
+// lands between reading the session and writing it back. Events are left to
+// the caller, since sync bus handlers may call back into the service.
+func (s *SessionService) modify(id string, change func(existing Session) (*Session, error)) (before, after *Session, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.store.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := change(*existing)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.store.Update(updated); err != nil {
+		return nil, nil, err
+	}
+
+	return existing, updated, nil
 }
 
 func (s *SessionService) DeleteSession(ctx context.Context, id string) error {
-	return s.store.Delete(id)
+	existing, err := s.store.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.Delete(id); err != nil {
+		return err
+	}
+	s.snapshots.Delete(id)
+
+	publish(ctx, s.eventBus, eventbus.SessionDeletedEvent{
+		SessionID:   id,
+		WorkspaceID: existing.WorkspaceID,
+	})
+	return nil
+}
+
+// GetSnapshot returns what Zellij last reported running in the session. Sessions
+// without a report get an empty snapshot.
+func (s *SessionService) GetSnapshot(ctx context.Context, id string) (*Snapshot, error) {
+	if _, err := s.store.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	if snapshot := s.snapshots.Get(id); snapshot != nil {
+		return snapshot, nil
+	}
+	return &Snapshot{Tabs: []Tab{}, Panes: []Pane{}}, nil
+}
+
+// SetSnapshot replaces the session's snapshot. Snapshots of dead sessions are
+// ignored since nothing runs in them anymore.
+func (s *SessionService) SetSnapshot(ctx context.Context, id string, snapshot *Snapshot) error {
+	session, err := s.store.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if session.IsDead() {
+		return nil
+	}
+
+	if snapshot.Tabs == nil {
+		snapshot.Tabs = []Tab{}
+	}
+	if snapshot.Panes == nil {
+		snapshot.Panes = []Pane{}
+	}
+	s.snapshots.Set(id, snapshot)
+	return nil
+}
+
+func (s *SessionService) dropSnapshotIfDead(session *Session) {
+	if session.IsDead() {
+		s.snapshots.Delete(session.ID)
+	}
+}
+
+// publishChanges emits domain events for what differs between two versions
+// of a session. Updates that only touch LastUsedAt publish nothing.
+func (s *SessionService) publishChanges(ctx context.Context, before, after *Session) {
+	if before.WorkspaceID != after.WorkspaceID {
+		publish(ctx, s.eventBus, eventbus.SessionWorkspaceChangedEvent{
+			SessionID: after.ID,
+			From:      before.WorkspaceID,
+			To:        after.WorkspaceID,
+		})
+	}
+
+	if before.State == after.State {
+		return
+	}
+
+	publish(ctx, s.eventBus, eventbus.SessionStateChangedEvent{
+		SessionID: after.ID,
+		From:      string(before.State),
+		To:        string(after.State),
+	})
+
+	switch {
+	case after.IsAttached():
+		publish(ctx, s.eventBus, eventbus.SessionAttachedEvent{SessionID: after.ID})
+	case before.IsAttached() && after.State == StateRunningDetached:
+		publish(ctx, s.eventBus, eventbus.SessionDetachedEvent{SessionID: after.ID})
+	case after.State == StateExited && !before.IsDead():
+		publish(ctx, s.eventBus, eventbus.SessionDiedEvent{SessionID: after.ID})
+	}
+}
+
+// publish delivers a domain event. The change has already been stored, so a
+// failing subscriber is logged rather than failing the caller.
+func publish[T eventbus.Payload](ctx context.Context, bus eventbus.EventBus, payload T) {
+	if err := eventbus.Publish(ctx, bus, payload); err != nil {
+		log.Printf("Failed to publish %T: %v", payload, err)
+	}
 }
@@ -2,9 +2,13 @@ package session
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/stretchr/testify/require"
@@ -19,14 +23,26 @@ func setupSessionService(t *testing.T) (*SessionService, *SessionStore, *workspa
 	workspaceStore := workspace.NewWorkspaceStore()
 
 	// Initialize workspace store with test data
-	ctx := context.Background()
-	err := workspaceStore.OnAppStart(ctx)
-	require.NoError(t, err)
+	seedWorkspaces(t, workspaceStore)
 
-	service := NewSessionService(sessionStore, workspaceStore, bus)
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), bus)
 	return service, sessionStore, workspaceStore
 }
 
+// seedWorkspaces adds the fixture workspaces the session tests refer to
+func seedWorkspaces(t *testing.T, store *workspace.WorkspaceStore) {
+	t.Helper()
+
+	workspaces := []*workspace.Workspace{
+		{ID: "ws-1", Name: "utena", Path: t.TempDir(), IsGitRepo: true},
+		{ID: "ws-2", Name: "example-project", Path: t.TempDir()},
+	}
+
+	for _, ws := range workspaces {
+		require.NoError(t, store.Add(ws))
+	}
+}
+
 func TestNewSessionService(t *testing.T) {
 	service, _, _ := setupSessionService(t)
 	require.NotNil(t, service)
@@ -108,8 +124,7 @@ func TestSessionService_GetSession(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		State:       StateAttached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(session)
@@ -119,7 +134,7 @@ func TestSessionService_GetSession(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, session.ID, retrieved.ID)
 	require.Equal(t, session.WorkspaceID, retrieved.WorkspaceID)
-	require.Equal(t, session.IsAttached, retrieved.IsAttached)
+	require.Equal(t, session.State, retrieved.State)
 }
 
 func TestSessionService_GetSession_NotFound(t *testing.T) {
@@ -137,8 +152,7 @@ func TestSessionService_CreateSession(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		State:       StateAttached,
 	}
 
 	ctx := context.Background()
@@ -175,14 +189,13 @@ func TestSessionService_UpdateSession(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    true,
+		State:       StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(session)
 
 	// Update session
-	session.IsAttached = true
+	session.State = StateAttached
 	ctx := context.Background()
 	err := service.UpdateSession(ctx, session)
 	require.NoError(t, err)
@@ -190,7 +203,7 @@ func TestSessionService_UpdateSession(t *testing.T) {
 	// Verify update
 	retrieved, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
-	require.True(t, retrieved.IsAttached)
+	require.True(t, retrieved.IsAttached())
 }
 
 func TestSessionService_UpdateSession_InvalidWorkspace(t *testing.T) {
@@ -238,3 +251,584 @@ func TestSessionService_DeleteSession_NotFound(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "not found")
 }
+
+func TestSessionService_ActivateSession(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	var published []eventbus.SessionActivateRequestedEvent
+	service.eventBus.Subscribe(eventbus.SessionActivateRequested, func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event.Data.(eventbus.SessionActivateRequestedEvent))
+		return nil
+	})
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: oldTime})
+
+	ctx := context.Background()
+	activation, err := service.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+	require.True(t, activation.Session.LastUsedAt.After(oldTime))
+	require.NotEmpty(t, activation.CommandID)
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.True(t, retrieved.LastUsedAt.After(oldTime))
+
+	require.Equal(t, []eventbus.SessionActivateRequestedEvent{{CommandID: activation.CommandID, SessionName: "session-1"}}, published)
+}
+
+func TestSessionService_ActivateSession_KeepsStateChangedDuringSwitch(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	// Zellij reports the switch before the publish returns
+	service.eventBus.Subscribe(eventbus.SessionActivateRequested, func(ctx context.Context, event eventbus.Event) error {
+		_, err := service.TransitionSession(ctx, "session-1", StateAttached)
+		return err
+	})
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: oldTime})
+
+	activation, err := service.ActivateSession(context.Background(), "session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateAttached, activation.Session.State)
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateAttached, retrieved.State)
+	require.False(t, retrieved.StateChangedAt[StateAttached].IsZero())
+	require.True(t, retrieved.LastUsedAt.After(oldTime))
+}
+
+func TestSessionService_ActivateSession_QueueFull(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	sessionStore := NewSessionStore()
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), backedUpBus(t))
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: oldTime})
+
+	_, err := service.ActivateSession(context.Background(), "session-1")
+	require.ErrorIs(t, err, eventbus.ErrQueueFull)
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.True(t, retrieved.LastUsedAt.Equal(oldTime))
+}
+
+func TestSessionService_ActivateSession_NotFound(t *testing.T) {
+	service, _, _ := setupSessionService(t)
+
+	ctx := context.Background()
+	_, err := service.ActivateSession(ctx, "nonexistent")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionService_ActivateSession_Dead(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateExited, LastUsedAt: oldTime})
+
+	ctx := context.Background()
+	_, err := service.ActivateSession(ctx, "session-1")
+	require.ErrorIs(t, err, ErrSessionDead)
+
+	// Recency is untouched
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.True(t, retrieved.LastUsedAt.Equal(oldTime))
+}
+
+func TestSessionService_ActivateSession_SwitchFails(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	service.eventBus.Subscribe(eventbus.SessionActivateRequested, func(ctx context.Context, event eventbus.Event) error {
+		return errors.New("zellij pipe failed")
+	})
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: oldTime})
+
+	// The failure shows up in the activation and the command's status, not
+	// as an error
+	ctx := context.Background()
+	activation, err := service.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+	require.NotEmpty(t, activation.CommandID)
+	require.ErrorContains(t, activation.Err, "zellij pipe failed")
+
+	// A failed switch leaves the MRU order alone
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.True(t, retrieved.LastUsedAt.Equal(oldTime))
+}
+
+func TestSessionService_ActivateSession_BusClosed(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	sessionStore := NewSessionStore()
+
+	bus := eventbus.NewAsyncEventBus()
+	require.NoError(t, bus.Close(context.Background()))
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), bus)
+
+	oldTime := time.Now().Add(-1 * time.Hour)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: oldTime})
+
+	_, err := service.ActivateSession(context.Background(), "session-1")
+	require.ErrorIs(t, err, eventbus.ErrBusClosed)
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.True(t, retrieved.LastUsedAt.Equal(oldTime))
+}
+
+func TestSessionService_CreateSessionAndNotify(t *testing.T) {
+	service, _, workspaceStore := setupSessionService(t)
+
+	var published []eventbus.SessionCreateRequestedEvent
+	service.eventBus.Subscribe(eventbus.SessionCreateRequested, func(ctx context.Context, event eventbus.Event) error {
+		published = append(published, event.Data.(eventbus.SessionCreateRequestedEvent))
+		return nil
+	})
+
+	ws, err := workspaceStore.GetByID("ws-1")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	creation, err := service.CreateSessionAndNotify(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+	require.Equal(t, "session-1", creation.Session.ID)
+
+	require.Len(t, published, 1)
+	require.Equal(t, creation.CommandID, published[0].CommandID)
+	require.Equal(t, "session-1", published[0].SessionName)
+	require.Equal(t, "ws-1", published[0].WorkspaceID)
+	require.Equal(t, "utena", published[0].WorkspaceName)
+	require.Equal(t, ws.Path, published[0].WorkspacePath)
+	require.True(t, filepath.IsAbs(published[0].WorkspacePath))
+}
+
+// racingStore lets a concurrent create take the name between validation and
+// the store write.
+type racingStore struct {
+	*SessionStore
+}
+
+func (s racingStore) Add(session *Session) error {
+	winner := *session
+	s.SessionStore.Add(&winner)
+	return s.SessionStore.Add(session)
+}
+
+func TestSessionService_CreateSessionAndNotify_ConcurrentDuplicate(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	service := NewSessionService(racingStore{NewSessionStore()}, workspaceStore, NewSessionNamer(config.Default()), eventbus.NewEventBus())
+
+	_, err := service.CreateSessionAndNotify(context.Background(), &Session{ID: "utena", WorkspaceID: "ws-1"})
+	require.ErrorIs(t, err, ErrSessionExists)
+
+	var duplicate *DuplicateSessionNameError
+	require.ErrorAs(t, err, &duplicate)
+	require.Equal(t, []string{"utena-2", "utena-3", "utena-4"}, duplicate.Suggestions)
+}
+
+func TestSessionService_CreateSessionAndNotify_PublishFails(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	service.eventBus.Subscribe(eventbus.SessionCreateRequested, func(ctx context.Context, event eventbus.Event) error {
+		return errors.New("zellij pipe failed")
+	})
+
+	// The failure shows up in the command's status and the dead letters, so
+	// the request still succeeds
+	ctx := context.Background()
+	creation, err := service.CreateSessionAndNotify(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1"})
+	require.NoError(t, err)
+	require.NotEmpty(t, creation.CommandID)
+
+	// The session is kept so a re-driven command can still create it
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateRequested, retrieved.State)
+}
+
+func TestSessionService_CreateSessionAndNotify_BusClosed(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	sessionStore := NewSessionStore()
+
+	bus := eventbus.NewAsyncEventBus()
+	require.NoError(t, bus.Close(context.Background()))
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), bus)
+
+	_, err := service.CreateSessionAndNotify(context.Background(), &Session{ID: "session-1", WorkspaceID: "ws-1"})
+	require.ErrorIs(t, err, eventbus.ErrBusClosed)
+
+	// Nothing will send the command, so the session isn't left waiting on it
+	_, err = sessionStore.GetByID("session-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+// backedUpBus returns an async bus whose only subscriber is stuck, with its
+// queue full.
+func backedUpBus(t *testing.T) *eventbus.AsyncEventBus {
+	t.Helper()
+
+	bus := eventbus.NewAsyncEventBus(eventbus.WithQueueSize(1), eventbus.WithErrorHandler(func(eventbus.Event, error) {}))
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	bus.Subscribe("*", func(ctx context.Context, event eventbus.Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	t.Cleanup(func() {
+		close(release)
+		bus.Close(context.Background())
+	})
+
+	// The first event occupies the worker, the second fills the queue
+	require.NoError(t, bus.Publish(context.Background(), eventbus.Event{Type: "test.event"}))
+	<-started
+	require.NoError(t, bus.Publish(context.Background(), eventbus.Event{Type: "test.event"}))
+	return bus
+}
+
+func TestSessionService_CreateSessionAndNotify_QueueFull(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	sessionStore := NewSessionStore()
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(config.Default()), backedUpBus(t))
+
+	_, err := service.CreateSessionAndNotify(context.Background(), &Session{ID: "session-1", WorkspaceID: "ws-1"})
+	require.ErrorIs(t, err, eventbus.ErrQueueFull)
+
+	// The command was dropped, so the session isn't left waiting on it
+	_, err = sessionStore.GetByID("session-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionService_CreateSessionAndNotify_MissingDirectory(t *testing.T) {
+	service, sessionStore, workspaceStore := setupSessionService(t)
+
+	missing := filepath.Join(t.TempDir(), "deleted")
+	workspaceStore.Add(&workspace.Workspace{ID: "ws-gone", Name: "deleted", Path: missing})
+
+	ctx := context.Background()
+	_, err := service.CreateSessionAndNotify(ctx, &Session{ID: "session-1", WorkspaceID: "ws-gone"})
+	require.ErrorIs(t, err, ErrWorkspacePathMissing)
+	require.Contains(t, err.Error(), missing)
+
+	// No session is created for a workspace that's gone
+	_, err = sessionStore.GetByID("session-1")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestSessionService_CreateSessionAndNotify_NoDirectory(t *testing.T) {
+	service, _, workspaceStore := setupSessionService(t)
+
+	workspaceStore.Add(&workspace.Workspace{ID: "ws-pathless", Name: "pathless"})
+
+	ctx := context.Background()
+	_, err := service.CreateSessionAndNotify(ctx, &Session{ID: "session-1", WorkspaceID: "ws-pathless"})
+	require.ErrorIs(t, err, ErrWorkspacePathMissing)
+}
+
+func TestSessionService_CreateSessionAndNotify_DefaultName(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	ctx := context.Background()
+	first := &Session{WorkspaceID: "ws-1"}
+	_, err := service.CreateSessionAndNotify(ctx, first)
+	require.NoError(t, err)
+	require.Equal(t, "utena-1", first.ID)
+
+	second := &Session{WorkspaceID: "ws-1"}
+	_, err = service.CreateSessionAndNotify(ctx, second)
+	require.NoError(t, err)
+	require.Equal(t, "utena-2", second.ID)
+
+	_, err = sessionStore.GetByID("utena-2")
+	require.NoError(t, err)
+}
+
+func TestSessionService_CreateSessionAndNotify_InvalidName(t *testing.T) {
+	service, _, _ := setupSessionService(t)
+
+	ctx := context.Background()
+	_, err := service.CreateSessionAndNotify(ctx, &Session{ID: "my project", WorkspaceID: "ws-1"})
+	require.ErrorIs(t, err, ErrInvalidSessionName)
+}
+
+func TestSessionService_CreateSessionAndNotify_DuplicateName(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	sessionStore.Add(&Session{ID: "utena", WorkspaceID: "ws-1", LastUsedAt: time.Now()})
+
+	ctx := context.Background()
+	_, err := service.CreateSessionAndNotify(ctx, &Session{ID: "utena", WorkspaceID: "ws-1"})
+	var duplicate *DuplicateSessionNameError
+	require.ErrorAs(t, err, &duplicate)
+	require.Equal(t, []string{"utena-2", "utena-3", "utena-4"}, duplicate.Suggestions)
+}
+
+func TestSessionService_CreateSession_TemplateName(t *testing.T) {
+	bus := eventbus.NewEventBus()
+	sessionStore := NewSessionStore()
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+
+	cfg := config.Default()
+	cfg.SessionNameTemplate = "{{.WorkspaceID}}-{{.Workspace}}-{{.Counter}}"
+	service := NewSessionService(sessionStore, workspaceStore, NewSessionNamer(cfg), bus)
+
+	ctx := context.Background()
+	session := &Session{WorkspaceID: "ws-2"}
+	require.NoError(t, service.CreateSession(ctx, session))
+	require.Equal(t, "ws-2-example-project-1", session.ID)
+}
+
+func TestSessionService_CreateSession_DefaultsToRequested(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	ctx := context.Background()
+	require.NoError(t, service.CreateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1"}))
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateRequested, retrieved.State)
+	require.False(t, retrieved.StateChangedAt[StateRequested].IsZero())
+}
+
+func TestSessionService_TransitionSession(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	ctx := context.Background()
+	require.NoError(t, service.CreateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1"}))
+
+	for _, state := range []State{StateStarting, StateAttached, StateRunningDetached, StateExited, StateArchived} {
+		updated, err := service.TransitionSession(ctx, "session-1", state)
+		require.NoError(t, err)
+		require.Equal(t, state, updated.State)
+	}
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateArchived, retrieved.State)
+	require.Len(t, retrieved.StateChangedAt, 6)
+	require.False(t, retrieved.StateChangedAt[StateArchived].Before(retrieved.StateChangedAt[StateExited]))
+}
+
+func TestSessionService_TransitionSession_Invalid(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+
+	ctx := context.Background()
+	_, err := service.TransitionSession(ctx, "session-1", StateArchived)
+	require.ErrorIs(t, err, ErrInvalidTransition)
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateAttached, retrieved.State)
+}
+
+// pausingStore holds the first read of a session until released, so another
+// change can race the read-check-write it belongs to.
+type pausingStore struct {
+	*SessionStore
+	reached chan struct{}
+	release chan struct{}
+	paused  atomic.Bool
+}
+
+func (s *pausingStore) GetByID(id string) (*Session, error) {
+	session, err := s.SessionStore.GetByID(id)
+	if s.paused.CompareAndSwap(false, true) {
+		close(s.reached)
+		<-s.release
+	}
+	return session, err
+}
+
+func TestSessionService_TransitionSession_ConcurrentUpdateNotRegressed(t *testing.T) {
+	workspaceStore := workspace.NewWorkspaceStore()
+	seedWorkspaces(t, workspaceStore)
+	store := &pausingStore{SessionStore: NewSessionStore(), reached: make(chan struct{}), release: make(chan struct{})}
+	store.SessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRequested, LastUsedAt: time.Now()})
+	service := NewSessionService(store, workspaceStore, NewSessionNamer(config.Default()), eventbus.NewEventBus())
+
+	ctx := context.Background()
+	transitioned := make(chan error, 1)
+	go func() {
+		_, err := service.TransitionSession(ctx, "session-1", StateStarting)
+		transitioned <- err
+	}()
+	<-store.reached
+
+	// Zellij reports the session attached while the transition holds its read
+	updated := make(chan error, 1)
+	go func() {
+		updated <- service.UpdateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+	}()
+	time.Sleep(20 * time.Millisecond)
+	close(store.release)
+
+	require.NoError(t, <-transitioned)
+	require.NoError(t, <-updated)
+
+	retrieved, err := store.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, StateAttached, retrieved.State)
+}
+
+func TestSessionService_UpdateSession_InvalidTransition(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateExited, LastUsedAt: time.Now()})
+
+	ctx := context.Background()
+	err := service.UpdateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRequested, LastUsedAt: time.Now()})
+	require.ErrorIs(t, err, ErrInvalidTransition)
+}
+
+func TestSessionService_UpdateSession_KeepsStateWhenOmitted(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+
+	ctx := context.Background()
+	require.NoError(t, service.UpdateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-2", LastUsedAt: time.Now()}))
+
+	retrieved, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-2", retrieved.WorkspaceID)
+	require.Equal(t, StateAttached, retrieved.State)
+}
+
+// recordEventTypes collects the types of the given events as they are published
+func recordEventTypes(t *testing.T, bus eventbus.EventBus, types ...string) *[]string {
+	t.Helper()
+
+	var published []string
+	for _, eventType := range types {
+		bus.Subscribe(eventType, func(ctx context.Context, event eventbus.Event) error {
+			published = append(published, event.Type)
+			return nil
+		})
+	}
+	return &published
+}
+
+var sessionDomainEvents = []string{
+	eventbus.SessionCreated,
+	eventbus.SessionStateChanged,
+	eventbus.SessionAttached,
+	eventbus.SessionDetached,
+	eventbus.SessionDied,
+	eventbus.SessionWorkspaceChanged,
+	eventbus.SessionDeleted,
+}
+
+func TestSessionService_PublishesLifecycleEvents(t *testing.T) {
+	service, _, _ := setupSessionService(t)
+	published := recordEventTypes(t, service.eventBus, sessionDomainEvents...)
+
+	ctx := context.Background()
+	require.NoError(t, service.CreateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached}))
+
+	for _, state := range []State{StateAttached, StateRunningDetached, StateExited} {
+		_, err := service.TransitionSession(ctx, "session-1", state)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, service.DeleteSession(ctx, "session-1"))
+
+	require.Equal(t, []string{
+		eventbus.SessionCreated,
+		eventbus.SessionStateChanged, eventbus.SessionAttached,
+		eventbus.SessionStateChanged, eventbus.SessionDetached,
+		eventbus.SessionStateChanged, eventbus.SessionDied,
+		eventbus.SessionDeleted,
+	}, *published)
+}
+
+func TestSessionService_UpdateSession_PublishesOnlyChanges(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()})
+	published := recordEventTypes(t, service.eventBus, sessionDomainEvents...)
+
+	ctx := context.Background()
+
+	// Bumping LastUsedAt alone is not a domain change
+	require.NoError(t, service.UpdateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1", State: StateAttached, LastUsedAt: time.Now()}))
+	require.Empty(t, *published)
+
+	require.NoError(t, service.UpdateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-2", LastUsedAt: time.Now()}))
+	require.Equal(t, []string{eventbus.SessionWorkspaceChanged}, *published)
+}
+
+func TestSessionService_PublishFailureDoesNotFailChange(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+	service.eventBus.Subscribe(eventbus.SessionCreated, func(ctx context.Context, event eventbus.Event) error {
+		return errors.New("subscriber failed")
+	})
+
+	ctx := context.Background()
+	require.NoError(t, service.CreateSession(ctx, &Session{ID: "session-1", WorkspaceID: "ws-1"}))
+
+	_, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+}
+
+func TestSessionService_Snapshot(t *testing.T) {
+	service, sessionStore, _ := setupSessionService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", State: StateRunningDetached, LastUsedAt: time.Now()})
+
+	// Nothing reported yet
+	snapshot, err := service.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Tabs)
+	require.NotNil(t, snapshot.Panes)
+
+	err = service.SetSnapshot(ctx, "session-1", &Snapshot{
+		Tabs:  []Tab{{Position: 0, Name: "editor", Active: true}},
+		Panes: []Pane{{ID: 1, Title: "nvim", Command: "nvim"}},
+	})
+	require.NoError(t, err)
+
+	snapshot, err = service.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Equal(t, "editor", snapshot.Tabs[0].Name)
+	require.Equal(t, "nvim", snapshot.Panes[0].Command)
+
+	// Nothing runs in a dead session
+	_, err = service.TransitionSession(ctx, "session-1", StateExited)
+	require.NoError(t, err)
+
+	snapshot, err = service.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Tabs)
+
+	require.NoError(t, service.SetSnapshot(ctx, "session-1", &Snapshot{Tabs: []Tab{{Name: "stale"}}}))
+	snapshot, err = service.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Tabs)
+}
+
+func TestSessionService_Snapshot_NotFound(t *testing.T) {
+	service, _, _ := setupSessionService(t)
+	ctx := context.Background()
+
+	_, err := service.GetSnapshot(ctx, "missing")
+	require.ErrorIs(t, err, ErrSessionNotFound)
+
+	err = service.SetSnapshot(ctx, "missing", &Snapshot{})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
@@ -0,0 +1,33 @@
+package session
+
+import (
+	"path/filepath"
+
+	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/storage"
+)
+
+// SessionStorage is implemented by every session store backend.
+type SessionStorage interface {
+	common.Module
+
+	GetByID(id string) (*Session, error)
+	List() []Session
+	ListByWorkspace(workspaceID string) []Session
+	Add(session *Session) error
+	Update(session *Session) error
+	Delete(id string) error
+}
+
+// NewSessionStorage builds the backend selected by the storage_backend setting.
+func NewSessionStorage(cfg *config.Config) SessionStorage {
+	switch cfg.StorageBackend {
+	case config.StorageMemory:
+		return NewSessionStore()
+	case config.StorageKV:
+		return NewKVSessionStore(storage.NewFileKV(filepath.Join(cfg.StorageDir, "sessions")))
+	default:
+		return NewSessionStore(WithPersistence(NewSessionFile(cfg.SessionsPath), defaultSaveDelay))
+	}
+}
@@ -3,19 +3,52 @@ package session
 import (
 	"context"
 	"errors"
+	"log"
 	"sort"
 	"sync"
+	"time"
 )
 
+const defaultSaveDelay = 500 * time.Millisecond
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExists   = errors.New("session with this ID already exists")
+)
+
+type SessionStoreOption func(*SessionStore)
+
+// WithPersistence loads sessions from file on start and writes them back after
+// mutations, batching writes that happen within delay of each other.
+func WithPersistence(file *SessionFile, delay time.Duration) SessionStoreOption {
+	return func(s *SessionStore) {
+		s.file = file
+		s.saveDelay = delay
+	}
+}
+
 type SessionStore struct {
 	mu       sync.RWMutex
 	sessions map[string]*Session
+
+	file      *SessionFile
+	saveDelay time.Duration
+	saveMu    sync.Mutex
+	saveTimer *time.Timer
+	writeMu   sync.Mutex
 }
 
-func NewSessionStore() *SessionStore {
-	return &SessionStore{
-		sessions: make(map[string]*Session),
+func NewSessionStore(opts ...SessionStoreOption) *SessionStore {
+	s := &SessionStore{
+		sessions:  make(map[string]*Session),
+		saveDelay: defaultSaveDelay,
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *SessionStore) GetByID(id string) (*Session, error) {
@@ -24,7 +57,7 @@ func (s *SessionStore) GetByID(id string) (*Session, error) {
 
 	session, ok := s.sessions[id]
 	if !ok {
-		return nil, errors.New("session not found")
+		return nil, ErrSessionNotFound
 	}
 
 	return session, nil
@@ -81,10 +114,11 @@ func (s *SessionStore) Add(session *Session) error {
 	defer s.mu.Unlock()
 
 	if _, exists := s.sessions[session.ID]; exists {
-		return errors.New("session with this ID already exists")
+		return ErrSessionExists
 	}
 
 	s.sessions[session.ID] = session
+	s.scheduleSave()
 	return nil
 }
 
@@ -101,10 +135,11 @@ func (s *SessionStore) Update(session *Session) error {
 	defer s.mu.Unlock()
 
 	if _, exists := s.sessions[session.ID]; !exists {
-		return errors.New("session not found")
+		return ErrSessionNotFound
 	}
 
 	s.sessions[session.ID] = session
+	s.scheduleSave()
 	return nil
 }
 
@@ -117,19 +152,99 @@ func (s *SessionStore) Delete(id string) error {
 	defer s.mu.Unlock()
 
 	if _, exists := s.sessions[id]; !exists {
-		return errors.New("session not found")
+		return ErrSessionNotFound
 	}
 
 	delete(s.sessions, id)
+	s.scheduleSave()
 	return nil
 }
 
 func (s *SessionStore) OnAppStart(ctx context.Context) error {
+	if s.file == nil {
+		return nil
+	}
+
+	sessions, err := s.file.Load()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range sessions {
+		session := sessions[i]
+		session.applyDefaultState()
+		if ValidateSession(&session) != nil {
+			log.Printf("Skipping invalid persisted session %q", session.ID)
+			continue
+		}
+		s.sessions[session.ID] = &session
+	}
 
 	return nil
 }
 
 func (s *SessionStore) OnAppEnd(ctx context.Context) error {
+	return s.Flush()
+}
 
-	return nil
+// Flush writes any pending changes to disk immediately.
+func (s *SessionStore) Flush() error {
+	if s.file == nil {
+		return nil
+	}
+
+	s.saveMu.Lock()
+	if s.saveTimer != nil {
+		s.saveTimer.Stop()
+		s.saveTimer = nil
+	}
+	s.saveMu.Unlock()
+
+	return s.save()
+}
+
+// scheduleSave debounces writes so a burst of mutations results in a single
+// write once things settle.
+func (s *SessionStore) scheduleSave() {
+	if s.file == nil {
+		return
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if s.saveTimer != nil {
+		return
+	}
+
+	s.saveTimer = time.AfterFunc(s.saveDelay, func() {
+		s.saveMu.Lock()
+		s.saveTimer = nil
+		s.saveMu.Unlock()
+
+		if err := s.save(); err != nil {
+			log.Printf("Failed to persist sessions: %v", err)
+		}
+	})
+}
+
+func (s *SessionStore) save() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.RLock()
+	sessions := make([]Session, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, *session)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID < sessions[j].ID
+	})
+
+	return s.file.Save(sessions)
 }
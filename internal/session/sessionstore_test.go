@@ -2,6 +2,8 @@ package session
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -27,8 +29,7 @@ func TestSessionStore_Add(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  true,
-		IsActive:    true,
+		State:       StateAttached,
 		LastUsedAt:  time.Now(),
 	}
 
@@ -85,8 +86,7 @@ func TestSessionStore_GetByID(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    true,
+		State:       StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 
@@ -96,8 +96,7 @@ func TestSessionStore_GetByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, session.ID, retrieved.ID)
 	require.Equal(t, session.WorkspaceID, retrieved.WorkspaceID)
-	require.Equal(t, session.IsAttached, retrieved.IsAttached)
-	require.Equal(t, session.IsActive, retrieved.IsActive)
+	require.Equal(t, session.State, retrieved.State)
 }
 
 func TestSessionStore_GetByID_NotFound(t *testing.T) {
@@ -178,15 +177,14 @@ func TestSessionStore_Update(t *testing.T) {
 	session := &Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    true,
+		State:       StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 
 	store.Add(session)
 
 	// Update session
-	session.IsAttached = true
+	session.State = StateAttached
 	session.LastUsedAt = time.Now().Add(1 * time.Hour)
 
 	err := store.Update(session)
@@ -195,7 +193,7 @@ func TestSessionStore_Update(t *testing.T) {
 	// Verify update
 	retrieved, err := store.GetByID("session-1")
 	require.NoError(t, err)
-	require.True(t, retrieved.IsAttached)
+	require.True(t, retrieved.IsAttached())
 }
 
 func TestSessionStore_Update_NotFound(t *testing.T) {
@@ -306,3 +304,56 @@ func TestSessionStore_OnAppEnd(t *testing.T) {
 	err := store.OnAppEnd(ctx)
 	require.NoError(t, err)
 }
+
+func TestSessionStore_Persistence_RoundTrip(t *testing.T) {
+	file := NewSessionFile(filepath.Join(t.TempDir(), "sessions.json"))
+	store := NewSessionStore(WithPersistence(file, time.Hour))
+
+	ctx := context.Background()
+	require.NoError(t, store.OnAppStart(ctx))
+
+	session := &Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}
+	require.NoError(t, store.Add(session))
+
+	// OnAppEnd flushes pending writes without waiting for the debounce
+	require.NoError(t, store.OnAppEnd(ctx))
+
+	reloaded := NewSessionStore(WithPersistence(file, time.Hour))
+	require.NoError(t, reloaded.OnAppStart(ctx))
+
+	retrieved, err := reloaded.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", retrieved.WorkspaceID)
+}
+
+func TestSessionStore_Persistence_DebouncedSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store := NewSessionStore(WithPersistence(NewSessionFile(path), time.Hour))
+
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+	require.NoError(t, store.Add(&Session{ID: "session-2", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+	require.NoError(t, store.Delete("session-1"))
+
+	// Nothing is written while the debounce window is open
+	_, err := os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	require.NoError(t, store.Flush())
+
+	sessions, err := NewSessionFile(path).Load()
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "session-2", sessions[0].ID)
+}
+
+func TestSessionStore_Persistence_SavesAfterDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	store := NewSessionStore(WithPersistence(NewSessionFile(path), 10*time.Millisecond))
+
+	require.NoError(t, store.Add(&Session{ID: "session-1", WorkspaceID: "ws-1", LastUsedAt: time.Now()}))
+
+	require.Eventually(t, func() bool {
+		sessions, err := NewSessionFile(path).Load()
+		return err == nil && len(sessions) == 1 && sessions[0].ID == "session-1"
+	}, time.Second, 10*time.Millisecond)
+}
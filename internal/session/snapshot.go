@@ -0,0 +1,34 @@
+package session
+
+import "time"
+
+// Snapshot is what Zellij last reported running inside a session. It is only
+// kept while the session is alive.
+type Snapshot struct {
+	Tabs             []Tab     `json:"tabs"`
+	Panes            []Pane    `json:"panes"`
+	ConnectedClients int       `json:"connected_clients"`
+	Cwd              string    `json:"cwd,omitempty"`
+	ReportedAt       time.Time `json:"reported_at"`
+}
+
+type Tab struct {
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+}
+
+type Pane struct {
+	// ID is only unique among panes of the same kind; terminal and plugin
+	// panes are numbered separately.
+	ID          uint32 `json:"id"`
+	TabPosition int    `json:"tab_position"`
+	Title       string `json:"title"`
+	IsPlugin    bool   `json:"is_plugin"`
+	IsFocused   bool   `json:"is_focused"`
+	IsFloating  bool   `json:"is_floating"`
+	// Command is the terminal command, or the plugin URL for plugin panes.
+	Command    string `json:"command,omitempty"`
+	Exited     bool   `json:"exited"`
+	ExitStatus *int   `json:"exit_status,omitempty"`
+}
@@ -0,0 +1,38 @@
+package session
+
+import "sync"
+
+// SnapshotStore keeps the latest snapshot per session in memory. Snapshots
+// are rebuilt from the next plugin update, so they are never persisted.
+type SnapshotStore struct {
+	mu        sync.RWMutex
+	snapshots map[string]*Snapshot
+}
+
+func NewSnapshotStore() *SnapshotStore {
+	return &SnapshotStore{
+		snapshots: make(map[string]*Snapshot),
+	}
+}
+
+// Get returns the session's snapshot, or nil when none has been reported.
+func (s *SnapshotStore) Get(sessionID string) *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.snapshots[sessionID]
+}
+
+func (s *SnapshotStore) Set(sessionID string, snapshot *Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.snapshots[sessionID] = snapshot
+}
+
+func (s *SnapshotStore) Delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.snapshots, sessionID)
+}
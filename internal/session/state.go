@@ -0,0 +1,59 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// State is a session's position in its lifecycle.
+type State string
+
+const (
+	// StateRequested: a client asked for the session, Zellij hasn't seen it yet.
+	StateRequested State = "requested"
+	// StateStarting: the create command was handed to Zellij.
+	StateStarting State = "starting"
+	// StateRunningDetached: running in Zellij with no client attached.
+	StateRunningDetached State = "running_detached"
+	// StateAttached: running in Zellij with a client attached.
+	StateAttached State = "attached"
+	// StateExited: no longer reported by Zellij.
+	StateExited State = "exited"
+	// StateArchived: exited and hidden by the user.
+	StateArchived State = "archived"
+)
+
+var ErrInvalidTransition = errors.New("invalid session state transition")
+
+// transitions lists the states reachable from each state. Exited and archived
+// sessions can come back when Zellij resurrects them.
+var transitions = map[State][]State{
+	StateRequested:       {StateStarting, StateRunningDetached, StateAttached, StateExited},
+	StateStarting:        {StateRunningDetached, StateAttached, StateExited},
+	StateRunningDetached: {StateAttached, StateExited},
+	StateAttached:        {StateRunningDetached, StateExited},
+	StateExited:          {StateRunningDetached, StateAttached, StateArchived},
+	StateArchived:        {StateRunningDetached, StateAttached},
+}
+
+func (s State) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether a session may move from one state to another.
+// Staying in the same state is always allowed.
+func CanTransition(from, to State) bool {
+	if from == to {
+		return to.Valid()
+	}
+	return slices.Contains(transitions[from], to)
+}
+
+func checkTransition(from, to State) error {
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, from, to)
+	}
+	return nil
+}
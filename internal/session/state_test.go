@@ -0,0 +1,38 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     State
+		to       State
+		expected bool
+	}{
+		{from: StateRequested, to: StateStarting, expected: true},
+		{from: StateRequested, to: StateAttached, expected: true},
+		{from: StateStarting, to: StateRunningDetached, expected: true},
+		{from: StateRunningDetached, to: StateAttached, expected: true},
+		{from: StateAttached, to: StateRunningDetached, expected: true},
+		{from: StateAttached, to: StateExited, expected: true},
+		{from: StateExited, to: StateAttached, expected: true},
+		{from: StateExited, to: StateArchived, expected: true},
+		{from: StateArchived, to: StateRunningDetached, expected: true},
+		{from: StateAttached, to: StateAttached, expected: true},
+		{from: StateAttached, to: StateArchived, expected: false},
+		{from: StateRunningDetached, to: StateStarting, expected: false},
+		{from: StateExited, to: StateRequested, expected: false},
+		{from: StateArchived, to: StateExited, expected: false},
+		{from: StateRequested, to: StateArchived, expected: false},
+		{from: State("bogus"), to: State("bogus"), expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			require.Equal(t, tt.expected, CanTransition(tt.from, tt.to))
+		})
+	}
+}
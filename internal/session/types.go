@@ -1,9 +1,11 @@
 package session
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 
+	"github.com/eleonorayaya/utena/internal/common"
 	"github.com/go-chi/render"
 )
 
@@ -15,11 +17,63 @@ func NewSessionResponse(session *Session) *SessionResponse {
 	return &SessionResponse{Session: session}
 }
 
+// UnmarshalJSON decodes into a fresh Session; the embedded pointer would
+// otherwise receive Session's UnmarshalJSON while still nil.
+func (sr *SessionResponse) UnmarshalJSON(data []byte) error {
+	sr.Session = &Session{}
+	return sr.Session.UnmarshalJSON(data)
+}
+
 func (sr *SessionResponse) Render(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
 
+// CreateSessionResponse is the requested session plus the ID of the create
+// command. Poll GET /zellij/commands/{command_id} for whether the plugin
+// opened it.
+type CreateSessionResponse struct {
+	*Session
+	CommandID string `json:"command_id"`
+}
+
+func NewCreateSessionResponse(creation *Creation) *CreateSessionResponse {
+	return &CreateSessionResponse{Session: creation.Session, CommandID: creation.CommandID}
+}
+
+// MarshalJSON adds command_id next to the session's own fields, which the
+// embedded Session's MarshalJSON would otherwise drop.
+func (csr CreateSessionResponse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		sessionJSON
+		CommandID string `json:"command_id"`
+	}{
+		sessionJSON: csr.Session.toJSON(),
+		CommandID:   csr.CommandID,
+	})
+}
+
+func (csr *CreateSessionResponse) UnmarshalJSON(data []byte) error {
+	csr.Session = &Session{}
+	if err := csr.Session.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	var command struct {
+		CommandID string `json:"command_id"`
+	}
+	if err := json.Unmarshal(data, &command); err != nil {
+		return err
+	}
+	csr.CommandID = command.CommandID
+	return nil
+}
+
+func (csr *CreateSessionResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
 type SessionListResponse struct {
 	Sessions []Session `json:"sessions"`
 }
@@ -42,23 +96,50 @@ func RenderSessionList(sessions []Session) []render.Renderer {
 	return list
 }
 
+type DuplicateSessionNameResponse struct {
+	*common.ErrResponse
+	Suggestions []string `json:"suggestions"`
+}
+
+func NewDuplicateSessionNameResponse(err *DuplicateSessionNameError) *DuplicateSessionNameResponse {
+	return &DuplicateSessionNameResponse{
+		ErrResponse: &common.ErrResponse{
+			Err:            err,
+			HTTPStatusCode: http.StatusConflict,
+			StatusText:     "Conflict with current state.",
+			ErrorText:      err.Error(),
+		},
+		Suggestions: err.Suggestions,
+	}
+}
+
 type CreateSessionRequest struct {
 	*Session
 }
 
+func (c *CreateSessionRequest) UnmarshalJSON(data []byte) error {
+	c.Session = &Session{}
+	return c.Session.UnmarshalJSON(data)
+}
+
 func (c *CreateSessionRequest) Bind(r *http.Request) error {
 
 	if c.Session == nil {
 		return errors.New("session cannot be nil")
 	}
 
-	return ValidateSession(c.Session)
+	return ValidateNewSession(c.Session)
 }
 
 type UpdateSessionRequest struct {
 	*Session
 }
 
+func (u *UpdateSessionRequest) UnmarshalJSON(data []byte) error {
+	u.Session = &Session{}
+	return u.Session.UnmarshalJSON(data)
+}
+
 func (u *UpdateSessionRequest) Bind(r *http.Request) error {
 
 	if u.Session == nil {
@@ -67,3 +148,58 @@ func (u *UpdateSessionRequest) Bind(r *http.Request) error {
 
 	return ValidateSession(u.Session)
 }
+
+// ActivateSessionResponse reports whether the switch was requested. Poll
+// GET /zellij/commands/{command_id} for whether the plugin carried it out.
+type ActivateSessionResponse struct {
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+	CommandID string `json:"command_id"`
+}
+
+func NewActivateSessionResponse(activation *Activation) *ActivateSessionResponse {
+	if activation.Err != nil {
+		return &ActivateSessionResponse{
+			Success:   false,
+			Message:   "Switching to session " + activation.Session.ID + " failed: " + activation.Err.Error(),
+			CommandID: activation.CommandID,
+		}
+	}
+
+	return &ActivateSessionResponse{
+		Success:   true,
+		Message:   "Session " + activation.Session.ID + " activated successfully",
+		CommandID: activation.CommandID,
+	}
+}
+
+func (asr *ActivateSessionResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+type TabListResponse struct {
+	Tabs []Tab `json:"tabs"`
+}
+
+func NewTabListResponse(snapshot *Snapshot) *TabListResponse {
+	return &TabListResponse{Tabs: snapshot.Tabs}
+}
+
+func (tlr *TabListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+type PaneListResponse struct {
+	Panes []Pane `json:"panes"`
+}
+
+func NewPaneListResponse(snapshot *Snapshot) *PaneListResponse {
+	return &PaneListResponse{Panes: snapshot.Panes}
+}
+
+func (plr *PaneListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
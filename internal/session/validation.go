@@ -1,6 +1,9 @@
 package session
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 func ValidateSession(session *Session) error {
 	if session == nil {
@@ -19,6 +22,30 @@ func ValidateSession(session *Session) error {
 		return errors.New("session LastUsedAt cannot be zero")
 	}
 
+	return ValidateState(session.State)
+}
+
+// ValidateState accepts known states and the empty state, which means "leave
+// unchanged" on updates and "requested" on creation.
+func ValidateState(state State) error {
+	if state != "" && !state.Valid() {
+		return fmt.Errorf("unknown session state %q", state)
+	}
+
+	return nil
+}
+
+// ValidateNewSession checks a create request. The ID may be omitted, in which
+// case a default name is generated.
+func ValidateNewSession(session *Session) error {
+	if session == nil {
+		return errors.New("session cannot be nil")
+	}
+
+	if session.WorkspaceID == "" {
+		return errors.New("session WorkspaceID cannot be empty")
+	}
+
 	return nil
 }
 
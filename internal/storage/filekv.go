@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/eleonorayaya/utena/internal/config"
+)
+
+const fileKVExt = ".json"
+
+// FileKV stores one file per key inside a directory. Writes go through a temp
+// file and rename so a crash never leaves a partially written value behind.
+type FileKV struct {
+	mu  sync.RWMutex
+	dir string
+}
+
+func NewFileKV(dir string) *FileKV {
+	return &FileKV{
+		dir: dir,
+	}
+}
+
+func (kv *FileKV) Get(key string) ([]byte, error) {
+	path, err := kv.pathFor(key)
+	if err != nil {
+		return nil, err
+	}
+
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key %q: %w", key, err)
+	}
+
+	return data, nil
+}
+
+func (kv *FileKV) Put(key string, value []byte) error {
+	path, err := kv.pathFor(key)
+	if err != nil {
+		return err
+	}
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	return WriteFileAtomic(path, value)
+}
+
+func (kv *FileKV) Delete(key string) error {
+	path, err := kv.pathFor(key)
+	if err != nil {
+		return err
+	}
+
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete key %q: %w", key, err)
+	}
+
+	return nil
+}
+
+func (kv *FileKV) List() (map[string][]byte, error) {
+	dir, err := config.ExpandPath(kv.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	kv.mu.RLock()
+	defer kv.mu.RUnlock()
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]byte{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	values := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, fileKVExt) {
+			continue
+		}
+
+		key, err := url.PathUnescape(strings.TrimSuffix(name, fileKVExt))
+		if err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %q: %w", key, err)
+		}
+		values[key] = data
+	}
+
+	return values, nil
+}
+
+func (kv *FileKV) pathFor(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("key cannot be empty")
+	}
+
+	dir, err := config.ExpandPath(kv.dir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, url.PathEscape(key)+fileKVExt), nil
+}
+
+// WriteFileAtomic replaces path with data by writing a temp file in the same
+// directory and renaming it into place.
+func WriteFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupFileKV(t *testing.T) (*FileKV, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "kv")
+	return NewFileKV(dir), dir
+}
+
+func TestFileKV_PutAndGet(t *testing.T) {
+	kv, _ := setupFileKV(t)
+
+	err := kv.Put("session-1", []byte(`{"id":"session-1"}`))
+	require.NoError(t, err)
+
+	data, err := kv.Get("session-1")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"session-1"}`, string(data))
+}
+
+func TestFileKV_Get_NotFound(t *testing.T) {
+	kv, _ := setupFileKV(t)
+
+	_, err := kv.Get("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileKV_Put_EmptyKey(t *testing.T) {
+	kv, _ := setupFileKV(t)
+
+	err := kv.Put("", []byte("{}"))
+	require.Error(t, err)
+}
+
+func TestFileKV_Put_Overwrites(t *testing.T) {
+	kv, dir := setupFileKV(t)
+
+	require.NoError(t, kv.Put("key", []byte("1")))
+	require.NoError(t, kv.Put("key", []byte("2")))
+
+	data, err := kv.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, "2", string(data))
+
+	// Only the value file remains, no temp files
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestFileKV_Delete(t *testing.T) {
+	kv, _ := setupFileKV(t)
+
+	require.NoError(t, kv.Put("key", []byte("1")))
+	require.NoError(t, kv.Delete("key"))
+
+	_, err := kv.Get("key")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	err = kv.Delete("key")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFileKV_List(t *testing.T) {
+	kv, _ := setupFileKV(t)
+
+	values, err := kv.List()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	// Keys are escaped so they can't escape the directory
+	require.NoError(t, kv.Put("a/b", []byte("1")))
+	require.NoError(t, kv.Put("c d", []byte("2")))
+
+	values, err = kv.List()
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"a/b": []byte("1"), "c d": []byte("2")}, values)
+}
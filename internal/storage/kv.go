@@ -0,0 +1,13 @@
+package storage
+
+import "errors"
+
+var ErrNotFound = errors.New("key not found")
+
+// KV is a minimal key/value store used by the durable store backends.
+type KV interface {
+	Get(key string) ([]byte, error)
+	Put(key string, value []byte) error
+	Delete(key string) error
+	List() (map[string][]byte, error)
+}
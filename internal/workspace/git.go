@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const shortCommitLength = 7
+
+// CurrentBranch returns the checked out branch of the git repository at path.
+// A detached HEAD yields the short commit hash; anything that isn't a
+// readable repository yields an empty string.
+func CurrentBranch(path string) string {
+	gitDir, ok := resolveGitDir(path)
+	if !ok {
+		return ""
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	ref := strings.TrimSpace(string(head))
+	if branch, ok := strings.CutPrefix(ref, "ref: refs/heads/"); ok {
+		return branch
+	}
+
+	if len(ref) >= shortCommitLength && !strings.HasPrefix(ref, "ref:") {
+		return ref[:shortCommitLength]
+	}
+
+	return ""
+}
+
+// resolveGitDir follows the "gitdir:" pointer worktrees leave in their .git
+// file.
+func resolveGitDir(path string) (string, bool) {
+	gitPath := filepath.Join(path, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", false
+	}
+
+	if info.IsDir() {
+		return gitPath, true
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", false
+	}
+
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(path, dir)
+	}
+	return dir, true
+}
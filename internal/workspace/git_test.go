@@ -0,0 +1,51 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeGitHead creates a .git directory under path with the given HEAD
+func writeGitHead(t *testing.T, path, head string) {
+	t.Helper()
+
+	gitDir := filepath.Join(path, ".git")
+	require.NoError(t, os.MkdirAll(gitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(head), 0o644))
+}
+
+func TestCurrentBranch(t *testing.T) {
+	repo := t.TempDir()
+	writeGitHead(t, repo, "ref: refs/heads/feature/x\n")
+
+	require.Equal(t, "feature/x", CurrentBranch(repo))
+}
+
+func TestCurrentBranch_DetachedHead(t *testing.T) {
+	repo := t.TempDir()
+	writeGitHead(t, repo, "3f1c2a9b8d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a\n")
+
+	require.Equal(t, "3f1c2a9", CurrentBranch(repo))
+}
+
+func TestCurrentBranch_Worktree(t *testing.T) {
+	main := t.TempDir()
+	writeGitHead(t, main, "ref: refs/heads/main\n")
+
+	worktreeGitDir := filepath.Join(main, ".git", "worktrees", "wt")
+	require.NoError(t, os.MkdirAll(worktreeGitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreeGitDir, "HEAD"), []byte("ref: refs/heads/hotfix\n"), 0o644))
+
+	worktree := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+worktreeGitDir+"\n"), 0o644))
+
+	require.Equal(t, "hotfix", CurrentBranch(worktree))
+}
+
+func TestCurrentBranch_NotARepo(t *testing.T) {
+	require.Equal(t, "", CurrentBranch(t.TempDir()))
+	require.Equal(t, "", CurrentBranch(""))
+}
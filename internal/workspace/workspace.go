@@ -1,8 +1,57 @@
 package workspace
 
+import (
+	"path/filepath"
+	"strings"
+)
+
+// UnassignedWorkspaceID identifies the pseudo-workspace that owns sessions
+// whose directory doesn't belong to any discovered workspace.
+const UnassignedWorkspaceID = "unassigned"
+
 type Workspace struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
 	Path      string `json:"path"`
 	IsGitRepo bool   `json:"is_git_repo"`
 }
+
+func newUnassignedWorkspace() *Workspace {
+	return &Workspace{
+		ID:   UnassignedWorkspaceID,
+		Name: UnassignedWorkspaceID,
+	}
+}
+
+// MatchPath returns the workspace whose directory contains path. When
+// workspaces are nested the deepest one wins.
+func MatchPath(workspaces []Workspace, path string) (*Workspace, bool) {
+	if path == "" {
+		return nil, false
+	}
+	path = filepath.Clean(path)
+
+	var best *Workspace
+	for i := range workspaces {
+		ws := &workspaces[i]
+		if ws.Path == "" || !containsPath(ws.Path, path) {
+			continue
+		}
+
+		if best == nil || len(ws.Path) > len(best.Path) {
+			best = ws
+		}
+	}
+
+	return best, best != nil
+}
+
+func containsPath(dir, path string) bool {
+	dir = filepath.Clean(dir)
+	if path == dir {
+		return true
+	}
+
+	// Compare on a separator boundary so /dev/api doesn't match /dev/api-v2
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
@@ -0,0 +1,45 @@
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPath(t *testing.T) {
+	workspaces := []Workspace{
+		{ID: UnassignedWorkspaceID, Name: UnassignedWorkspaceID},
+		{ID: "ws-dev", Name: "dev", Path: "/home/me/dev"},
+		{ID: "ws-api", Name: "api", Path: "/home/me/dev/api"},
+		{ID: "ws-web", Name: "web", Path: "/home/me/dev/web/"},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "exact match", path: "/home/me/dev/api", expected: "ws-api"},
+		{name: "nested directory", path: "/home/me/dev/api/cmd/server", expected: "ws-api"},
+		{name: "longest prefix wins", path: "/home/me/dev/other", expected: "ws-dev"},
+		{name: "trailing slash on workspace", path: "/home/me/dev/web/src", expected: "ws-web"},
+		{name: "unclean path", path: "/home/me/dev/api/../web/./src", expected: "ws-web"},
+		{name: "sibling with shared prefix", path: "/home/me/dev/api-v2", expected: "ws-dev"},
+		{name: "outside every workspace", path: "/tmp", expected: ""},
+		{name: "empty path", path: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, ok := MatchPath(workspaces, tt.path)
+			if tt.expected == "" {
+				require.False(t, ok)
+				require.Nil(t, ws)
+				return
+			}
+
+			require.True(t, ok)
+			require.Equal(t, tt.expected, ws.ID)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package workspace
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/eleonorayaya/utena/internal/config"
+)
+
+type DiscoveryOption func(*WorkspaceDiscovery)
+
+// WithRootDir adds a directory whose subdirectories are offered as workspaces.
+func WithRootDir(dir string) DiscoveryOption {
+	return func(d *WorkspaceDiscovery) {
+		d.roots = append(d.roots, dir)
+	}
+}
+
+// WithMaxDepth limits how many levels below a root are scanned. A depth of 1
+// only considers the root's immediate children; zero or less removes the limit.
+func WithMaxDepth(depth int) DiscoveryOption {
+	return func(d *WorkspaceDiscovery) {
+		d.maxDepth = depth
+	}
+}
+
+// WithHidden includes dot-directories in the scan.
+func WithHidden(include bool) DiscoveryOption {
+	return func(d *WorkspaceDiscovery) {
+		d.includeHidden = include
+	}
+}
+
+// WithIgnore skips directories whose name matches any of the glob patterns.
+func WithIgnore(patterns ...string) DiscoveryOption {
+	return func(d *WorkspaceDiscovery) {
+		d.ignore = append(d.ignore, patterns...)
+	}
+}
+
+type WorkspaceDiscovery struct {
+	roots         []string
+	maxDepth      int
+	includeHidden bool
+	ignore        []string
+}
+
+func NewWorkspaceDiscovery(opts ...DiscoveryOption) *WorkspaceDiscovery {
+	d := &WorkspaceDiscovery{
+		maxDepth: 1,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Discover scans every root and returns the workspaces found, sorted by name.
+// Roots that do not exist are skipped. Git repositories are never descended
+// into, so nested checkouts don't show up as separate workspaces.
+func (d *WorkspaceDiscovery) Discover(ctx context.Context) ([]*Workspace, error) {
+	seen := make(map[string]bool)
+	workspaces := make([]*Workspace, 0)
+
+	for _, root := range d.roots {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		found, err := d.scanRoot(root)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ws := range found {
+			if seen[ws.Path] {
+				continue
+			}
+			seen[ws.Path] = true
+			workspaces = append(workspaces, ws)
+		}
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return lessWorkspace(workspaces[i], workspaces[j])
+	})
+
+	return workspaces, nil
+}
+
+func (d *WorkspaceDiscovery) scanRoot(root string) ([]*Workspace, error) {
+	rootPath, err := config.ExpandPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(rootPath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("Skipping missing workspace root: %s", rootPath)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat workspace root %s: %w", rootPath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("workspace root %s is not a directory", rootPath)
+	}
+
+	workspaces := make([]*Workspace, 0)
+	if err := d.scanDir(rootPath, 1, &workspaces); err != nil {
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+// scanDir collects the workspaces below dir. Git repositories and directories
+// at the depth limit are workspaces as they stand. Any other directory is only
+// a workspace when it has no subdirectories; otherwise it merely groups the
+// workspaces below it, like an org directory full of checkouts.
+func (d *WorkspaceDiscovery) scanDir(dir string, depth int, workspaces *[]*Workspace) error {
+	subdirs, err := d.subdirs(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range subdirs {
+		ws := newDiscoveredWorkspace(path)
+		if ws.IsGitRepo || (d.maxDepth > 0 && depth >= d.maxDepth) {
+			*workspaces = append(*workspaces, ws)
+			continue
+		}
+
+		children, err := d.subdirs(path)
+		if err != nil {
+			return err
+		}
+		if len(children) == 0 {
+			*workspaces = append(*workspaces, ws)
+			continue
+		}
+
+		if err := d.scanDir(path, depth+1, workspaces); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// subdirs lists the directories in dir that aren't filtered out.
+func (d *WorkspaceDiscovery) subdirs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	subdirs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || d.skip(entry.Name()) {
+			continue
+		}
+		subdirs = append(subdirs, filepath.Join(dir, entry.Name()))
+	}
+
+	return subdirs, nil
+}
+
+func (d *WorkspaceDiscovery) skip(name string) bool {
+	if !d.includeHidden && strings.HasPrefix(name, ".") {
+		return true
+	}
+
+	for _, pattern := range d.ignore {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newDiscoveredWorkspace(path string) *Workspace {
+	return &Workspace{
+		ID:        workspaceIDForPath(path),
+		Name:      filepath.Base(path),
+		Path:      path,
+		IsGitRepo: isGitRepo(path),
+	}
+}
+
+// workspaceIDForPath derives a stable ID so sessions keep pointing at the
+// same workspace across daemon restarts.
+func workspaceIDForPath(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return "ws-" + hex.EncodeToString(sum[:])[:10]
+}
+
+func isGitRepo(path string) bool {
+	// .git is a directory in regular clones and a file in worktrees.
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+func lessWorkspace(a, b *Workspace) bool {
+	an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+	if an != bn {
+		return an < bn
+	}
+	return a.Path < b.Path
+}
@@ -0,0 +1,136 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupWorkspaceRoot creates a temporary root containing the given relative
+// directories. A path ending in .git marks its parent as a git repository.
+func setupWorkspaceRoot(t *testing.T, dirs ...string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for _, dir := range dirs {
+		err := os.MkdirAll(filepath.Join(root, dir), 0o755)
+		require.NoError(t, err)
+	}
+
+	return root
+}
+
+func workspaceNames(workspaces []*Workspace) []string {
+	names := make([]string, len(workspaces))
+	for i, ws := range workspaces {
+		names[i] = ws.Name
+	}
+	return names
+}
+
+func TestWorkspaceDiscovery_Discover(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena/.git", "website", "Api")
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(root))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Api", "utena", "website"}, workspaceNames(workspaces))
+
+	utena := workspaces[1]
+	require.Equal(t, filepath.Join(root, "utena"), utena.Path)
+	require.True(t, utena.IsGitRepo)
+	require.NotEmpty(t, utena.ID)
+	require.False(t, workspaces[2].IsGitRepo)
+}
+
+func TestWorkspaceDiscovery_Discover_StableIDs(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena")
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(root))
+	first, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	second, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, first[0].ID, second[0].ID)
+}
+
+func TestWorkspaceDiscovery_Discover_SkipsFiles(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena")
+	err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("hi"), 0o644)
+	require.NoError(t, err)
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(root))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"utena"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_Hidden(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena", ".dotfiles")
+
+	workspaces, err := NewWorkspaceDiscovery(WithRootDir(root)).Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"utena"}, workspaceNames(workspaces))
+
+	workspaces, err = NewWorkspaceDiscovery(WithRootDir(root), WithHidden(true)).Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{".dotfiles", "utena"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_Ignore(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena", "node_modules", "tmp-build")
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(root), WithIgnore("node_modules", "tmp-*"))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"utena"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_Depth(t *testing.T) {
+	root := setupWorkspaceRoot(t, "org/api", "org/web/.git", "org/web/nested", "repo/.git/objects")
+
+	workspaces, err := NewWorkspaceDiscovery(WithRootDir(root)).Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"org", "repo"}, workspaceNames(workspaces))
+
+	// Git repositories are not descended into, and org only groups workspaces
+	workspaces, err = NewWorkspaceDiscovery(WithRootDir(root), WithMaxDepth(3)).Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "repo", "web"}, workspaceNames(workspaces))
+
+	// Without a limit the scan stops at leaves
+	workspaces, err = NewWorkspaceDiscovery(WithRootDir(root), WithMaxDepth(0)).Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "repo", "web"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_FilteredChildrenMakeALeaf(t *testing.T) {
+	root := setupWorkspaceRoot(t, "org/api", "site/node_modules/left-pad", "site/.cache")
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(root), WithMaxDepth(2), WithIgnore("node_modules"))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "site"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_MultipleRoots(t *testing.T) {
+	dev := setupWorkspaceRoot(t, "utena")
+	sites := setupWorkspaceRoot(t, "blog")
+
+	discovery := NewWorkspaceDiscovery(WithRootDir(dev), WithRootDir(sites), WithRootDir(dev))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"blog", "utena"}, workspaceNames(workspaces))
+}
+
+func TestWorkspaceDiscovery_Discover_MissingRoot(t *testing.T) {
+	discovery := NewWorkspaceDiscovery(WithRootDir(filepath.Join(t.TempDir(), "missing")))
+	workspaces, err := discovery.Discover(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, workspaces)
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/eleonorayaya/utena/internal/storage"
+)
+
+// KVWorkspaceStore keeps workspaces in an in-memory index for reads and
+// writes every mutation through to a key/value backend.
+type KVWorkspaceStore struct {
+	mu    sync.Mutex
+	cache *WorkspaceStore
+	kv    storage.KV
+}
+
+func NewKVWorkspaceStore(kv storage.KV) *KVWorkspaceStore {
+	return &KVWorkspaceStore{
+		cache: NewWorkspaceStore(),
+		kv:    kv,
+	}
+}
+
+func (s *KVWorkspaceStore) GetByID(id string) (*Workspace, error) {
+	return s.cache.GetByID(id)
+}
+
+func (s *KVWorkspaceStore) GetByPath(path string) (*Workspace, error) {
+	return s.cache.GetByPath(path)
+}
+
+func (s *KVWorkspaceStore) List() []Workspace {
+	return s.cache.List()
+}
+
+func (s *KVWorkspaceStore) Add(ws *Workspace) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.cache.Add(ws); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(ws)
+	if err != nil {
+		s.cache.Delete(ws.ID)
+		return fmt.Errorf("failed to marshal workspace: %w", err)
+	}
+
+	if err := s.kv.Put(ws.ID, data); err != nil {
+		s.cache.Delete(ws.ID)
+		return fmt.Errorf("failed to write workspace: %w", err)
+	}
+
+	return nil
+}
+
+func (s *KVWorkspaceStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, err := s.cache.GetByID(id)
+	if err != nil {
+		return s.cache.Delete(id)
+	}
+
+	if err := s.cache.Delete(id); err != nil {
+		return err
+	}
+
+	if err := s.kv.Delete(id); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.cache.Add(previous)
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+
+	return nil
+}
+
+func (s *KVWorkspaceStore) OnAppStart(ctx context.Context) error {
+	values, err := s.kv.List()
+	if err != nil {
+		return err
+	}
+
+	for key, data := range values {
+		var ws Workspace
+		if err := json.Unmarshal(data, &ws); err != nil {
+			log.Printf("Skipping unreadable workspace %q: %v", key, err)
+			continue
+		}
+
+		if err := s.cache.Add(&ws); err != nil {
+			log.Printf("Skipping invalid workspace %q: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *KVWorkspaceStore) OnAppEnd(ctx context.Context) error {
+	return nil
+}
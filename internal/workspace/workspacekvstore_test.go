@@ -0,0 +1,34 @@
+package workspace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/eleonorayaya/utena/internal/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVWorkspaceStore_ImplementsWorkspaceStorage(t *testing.T) {
+	var _ WorkspaceStorage = NewKVWorkspaceStore(nil)
+	var _ WorkspaceStorage = NewWorkspaceStore()
+}
+
+func TestKVWorkspaceStore_AddAndReload(t *testing.T) {
+	kv := storage.NewFileKV(filepath.Join(t.TempDir(), "workspaces"))
+	store := NewKVWorkspaceStore(kv)
+
+	require.NoError(t, store.Add(&Workspace{ID: "ws-1", Name: "utena", Path: "/dev/utena", IsGitRepo: true}))
+
+	reloaded := NewKVWorkspaceStore(kv)
+	require.NoError(t, reloaded.OnAppStart(context.Background()))
+
+	ws, err := reloaded.GetByPath("/dev/utena")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", ws.ID)
+	require.True(t, ws.IsGitRepo)
+
+	require.NoError(t, reloaded.Delete("ws-1"))
+	_, err = kv.Get("ws-1")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -3,19 +3,22 @@ package workspace
 import (
 	"context"
 
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/go-chi/chi/v5"
 )
 
 type WorkspaceModule struct {
-	Store      *WorkspaceStore
+	Store      WorkspaceStorage
 	Service    *WorkspaceService
 	Controller *WorkspaceController
 	Router     *WorkspaceRouter
 }
 
-func NewWorkspaceModule() *WorkspaceModule {
-	store := NewWorkspaceStore()
-	service := NewWorkspaceService(store)
+func NewWorkspaceModule(cfg *config.Config, bus eventbus.EventBus) *WorkspaceModule {
+	store := NewWorkspaceStorage(cfg)
+	discovery := newDiscoveryFromConfig(cfg)
+	service := NewWorkspaceService(store, discovery, bus)
 	controller := NewWorkspaceController(service)
 	router := NewWorkspaceRouter(controller)
 
@@ -56,3 +59,17 @@ func (m *WorkspaceModule) OnAppEnd(ctx context.Context) error {
 func (m *WorkspaceModule) Routes() chi.Router {
 	return m.Router.Routes()
 }
+
+func newDiscoveryFromConfig(cfg *config.Config) *WorkspaceDiscovery {
+	opts := []DiscoveryOption{
+		WithMaxDepth(cfg.WorkspaceMaxDepth),
+		WithHidden(cfg.WorkspaceIncludeHidden),
+		WithIgnore(cfg.WorkspaceIgnore...),
+	}
+
+	for _, root := range cfg.WorkspaceRoots {
+		opts = append(opts, WithRootDir(root))
+	}
+
+	return NewWorkspaceDiscovery(opts...)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,12 +15,14 @@ import (
 func setupWorkspaceRouter(t *testing.T) (*WorkspaceRouter, *WorkspaceStore) {
 	t.Helper()
 
+	root := setupWorkspaceRoot(t, "utena/.git", "example-project")
+
 	store := NewWorkspaceStore()
+	service := NewWorkspaceService(store, NewWorkspaceDiscovery(WithRootDir(root)), eventbus.NewEventBus())
 	ctx := context.Background()
-	err := store.OnAppStart(ctx)
+	err := service.OnAppStart(ctx)
 	require.NoError(t, err)
 
-	service := NewWorkspaceService(store)
 	controller := NewWorkspaceController(service)
 	router := NewWorkspaceRouter(controller)
 
@@ -44,20 +47,26 @@ func TestWorkspaceRouter_ListWorkspaces(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, response.Workspaces, 2)
 
-	// Verify workspace IDs
-	ids := make(map[string]bool)
-	for _, ws := range response.Workspaces {
-		ids[ws.ID] = true
-	}
-	require.True(t, ids["ws-1"])
-	require.True(t, ids["ws-2"])
+	// Verify discovered workspaces are sorted by name
+	require.Equal(t, "example-project", response.Workspaces[0].Name)
+	require.False(t, response.Workspaces[0].IsGitRepo)
+	require.Equal(t, "utena", response.Workspaces[1].Name)
+	require.True(t, response.Workspaces[1].IsGitRepo)
 }
 
 func TestWorkspaceRouter_GetWorkspaceByID(t *testing.T) {
-	router, _ := setupWorkspaceRouter(t)
+	router, store := setupWorkspaceRouter(t)
+
+	var ws Workspace
+	for _, candidate := range store.List() {
+		if candidate.Name == "utena" {
+			ws = candidate
+		}
+	}
+	require.NotEmpty(t, ws.ID)
 
 	// Create request
-	req := httptest.NewRequest("GET", "/ws-1", nil)
+	req := httptest.NewRequest("GET", "/"+ws.ID, nil)
 	w := httptest.NewRecorder()
 
 	// Execute
@@ -69,9 +78,9 @@ func TestWorkspaceRouter_GetWorkspaceByID(t *testing.T) {
 	var response WorkspaceResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	require.NoError(t, err)
-	require.Equal(t, "ws-1", response.ID)
+	require.Equal(t, ws.ID, response.ID)
 	require.Equal(t, "utena", response.Name)
-	require.Equal(t, "/Users/eleonora/dev/utena", response.Path)
+	require.Equal(t, ws.Path, response.Path)
 	require.True(t, response.IsGitRepo)
 }
 
@@ -2,30 +2,126 @@ package workspace
 
 import (
 	"context"
+	"log"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
 )
 
 type WorkspaceService struct {
-	store *WorkspaceStore
+	store     WorkspaceStorage
+	discovery *WorkspaceDiscovery
+	eventBus  eventbus.EventBus
 }
 
-func NewWorkspaceService(store *WorkspaceStore) *WorkspaceService {
+func NewWorkspaceService(store WorkspaceStorage, discovery *WorkspaceDiscovery, bus eventbus.EventBus) *WorkspaceService {
 	return &WorkspaceService{
-		store: store,
+		store:     store,
+		discovery: discovery,
+		eventBus:  bus,
 	}
 }
 
 func (s *WorkspaceService) OnAppStart(ctx context.Context) error {
+	if err := s.syncWorkspaces(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.store.GetByID(UnassignedWorkspaceID); err == nil {
+		return nil
+	}
+
+	return s.store.Add(newUnassignedWorkspace())
+}
+
+// syncWorkspaces makes the store match what is currently on disk. Durable
+// backends may still hold workspaces from a previous run.
+func (s *WorkspaceService) syncWorkspaces(ctx context.Context) error {
+	discovered, err := s.discovery.Discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]*Workspace, len(discovered))
+	for _, ws := range discovered {
+		found[ws.ID] = ws
+	}
+
+	// Published once the store is fully synced
+	var notify []func()
+	changed := make(map[string]bool)
+	for _, existing := range s.store.List() {
+		if existing.ID == UnassignedWorkspaceID {
+			continue
+		}
+
+		ws, ok := found[existing.ID]
+		if ok && *ws == existing {
+			delete(found, existing.ID)
+			continue
+		}
+
+		if err := s.store.Delete(existing.ID); err != nil {
+			return err
+		}
+
+		if ok {
+			changed[existing.ID] = true
+		} else {
+			removed := eventbus.WorkspaceRemovedEvent{WorkspaceID: existing.ID, Name: existing.Name, Path: existing.Path}
+			notify = append(notify, func() { publish(ctx, s.eventBus, removed) })
+		}
+	}
+
+	for _, ws := range discovered {
+		if _, ok := found[ws.ID]; !ok {
+			continue
+		}
+
+		if err := s.store.Add(ws); err != nil {
+			return err
+		}
+
+		if changed[ws.ID] {
+			updated := eventbus.WorkspaceUpdatedEvent{WorkspaceID: ws.ID, Name: ws.Name, Path: ws.Path}
+			notify = append(notify, func() { publish(ctx, s.eventBus, updated) })
+		} else {
+			added := eventbus.WorkspaceAddedEvent{WorkspaceID: ws.ID, Name: ws.Name, Path: ws.Path}
+			notify = append(notify, func() { publish(ctx, s.eventBus, added) })
+		}
+	}
+
+	for _, fn := range notify {
+		fn()
+	}
 
 	return nil
 }
 
+// publish reports a change that was already stored, so a failing subscriber
+// is logged rather than failing the sync.
+func publish[T eventbus.Payload](ctx context.Context, bus eventbus.EventBus, payload T) {
+	if err := eventbus.Publish(ctx, bus, payload); err != nil {
+		log.Printf("Failed to publish %T: %v", payload, err)
+	}
+}
+
 func (s *WorkspaceService) OnAppEnd(ctx context.Context) error {
 
 	return nil
 }
 
 func (s *WorkspaceService) ListWorkspaces(ctx context.Context) ([]Workspace, error) {
-	return s.store.List(), nil
+	all := s.store.List()
+
+	workspaces := make([]Workspace, 0, len(all))
+	for _, ws := range all {
+		if ws.ID == UnassignedWorkspaceID {
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+
+	return workspaces, nil
 }
 
 func (s *WorkspaceService) GetWorkspace(ctx context.Context, id string) (*Workspace, error) {
@@ -2,8 +2,12 @@ package workspace
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/eleonorayaya/utena/internal/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -11,7 +15,7 @@ import (
 func setupWorkspaceService(t *testing.T) (*WorkspaceService, *WorkspaceStore) {
 	t.Helper()
 	store := NewWorkspaceStore()
-	service := NewWorkspaceService(store)
+	service := NewWorkspaceService(store, NewWorkspaceDiscovery(), eventbus.NewEventBus())
 	return service, store
 }
 
@@ -27,8 +31,34 @@ func TestWorkspaceService_OnAppStart(t *testing.T) {
 	err := service.OnAppStart(ctx)
 	require.NoError(t, err)
 
-	// Service OnAppStart is a no-op
-	// Store handles initialization
+	// With no roots configured only the unassigned workspace is registered
+	ws, err := service.GetWorkspace(ctx, UnassignedWorkspaceID)
+	require.NoError(t, err)
+	require.Empty(t, ws.Path)
+
+	workspaces, err := service.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Empty(t, workspaces)
+}
+
+func TestWorkspaceService_OnAppStart_DiscoversWorkspaces(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena/.git", "example-project")
+
+	store := NewWorkspaceStore()
+	service := NewWorkspaceService(store, NewWorkspaceDiscovery(WithRootDir(root)), eventbus.NewEventBus())
+
+	ctx := context.Background()
+	err := service.OnAppStart(ctx)
+	require.NoError(t, err)
+
+	workspaces, err := service.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+
+	ws, err := service.GetWorkspaceByPath(ctx, filepath.Join(root, "utena"))
+	require.NoError(t, err)
+	require.Equal(t, "utena", ws.Name)
+	require.True(t, ws.IsGitRepo)
 }
 
 func TestWorkspaceService_OnAppEnd(t *testing.T) {
@@ -113,3 +143,87 @@ func TestWorkspaceService_GetWorkspaceByPath_NotFound(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "not found")
 }
+
+func TestWorkspaceService_OnAppStart_RemovesStaleWorkspaces(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena")
+	kv := storage.NewFileKV(filepath.Join(t.TempDir(), "workspaces"))
+
+	// A workspace left over from a previous run whose directory is gone
+	store := NewKVWorkspaceStore(kv)
+	require.NoError(t, store.Add(&Workspace{ID: "ws-stale", Name: "gone", Path: "/gone"}))
+
+	store = NewKVWorkspaceStore(kv)
+	require.NoError(t, store.OnAppStart(context.Background()))
+
+	service := NewWorkspaceService(store, NewWorkspaceDiscovery(WithRootDir(root)), eventbus.NewEventBus())
+	require.NoError(t, service.OnAppStart(context.Background()))
+
+	workspaces, err := service.ListWorkspaces(context.Background())
+	require.NoError(t, err)
+	require.Len(t, workspaces, 1)
+	require.Equal(t, "utena", workspaces[0].Name)
+
+	_, err = kv.Get("ws-stale")
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Starting again over the same storage is idempotent
+	require.NoError(t, service.OnAppStart(context.Background()))
+}
+
+// recordEvents subscribes to the given event types and collects what is published
+func recordEvents(t *testing.T, bus eventbus.EventBus, types ...string) *[]eventbus.Event {
+	t.Helper()
+
+	var events []eventbus.Event
+	for _, eventType := range types {
+		bus.Subscribe(eventType, func(ctx context.Context, event eventbus.Event) error {
+			events = append(events, event)
+			return nil
+		})
+	}
+	return &events
+}
+
+func TestWorkspaceService_OnAppStart_PublishesChanges(t *testing.T) {
+	root := setupWorkspaceRoot(t, "utena", "website")
+	bus := eventbus.NewEventBus()
+	events := recordEvents(t, bus, eventbus.WorkspaceAdded, eventbus.WorkspaceUpdated, eventbus.WorkspaceRemoved)
+
+	service := NewWorkspaceService(NewWorkspaceStore(), NewWorkspaceDiscovery(WithRootDir(root)), bus)
+	ctx := context.Background()
+	require.NoError(t, service.OnAppStart(ctx))
+	require.Len(t, *events, 2)
+	require.Equal(t, eventbus.WorkspaceAdded, (*events)[0].Type)
+
+	// Starting again over the same store with nothing changed on disk
+	// publishes nothing
+	*events = nil
+	require.NoError(t, service.OnAppStart(ctx))
+	require.Empty(t, *events)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "website")))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "api"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "utena", ".git"), 0o755))
+
+	require.NoError(t, service.OnAppStart(ctx))
+	workspaces, err := service.ListWorkspaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+
+	byType := make(map[string]string)
+	for _, event := range *events {
+		switch data := event.Data.(type) {
+		case eventbus.WorkspaceAddedEvent:
+			byType[event.Type] = data.Name
+		case eventbus.WorkspaceUpdatedEvent:
+			byType[event.Type] = data.Name
+		case eventbus.WorkspaceRemovedEvent:
+			byType[event.Type] = data.Name
+		}
+	}
+	require.Equal(t, map[string]string{
+		eventbus.WorkspaceAdded:   "api",
+		eventbus.WorkspaceUpdated: "utena",
+		eventbus.WorkspaceRemoved: "website",
+	}, byType)
+}
@@ -0,0 +1,32 @@
+package workspace
+
+import (
+	"path/filepath"
+
+	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/eleonorayaya/utena/internal/config"
+	"github.com/eleonorayaya/utena/internal/storage"
+)
+
+// WorkspaceStorage is implemented by every workspace store backend.
+type WorkspaceStorage interface {
+	common.Module
+
+	GetByID(id string) (*Workspace, error)
+	GetByPath(path string) (*Workspace, error)
+	List() []Workspace
+	Add(ws *Workspace) error
+	Delete(id string) error
+}
+
+// NewWorkspaceStorage builds the backend selected by the storage_backend
+// setting. Workspaces are rediscovered on start, so only the kv backend
+// persists them.
+func NewWorkspaceStorage(cfg *config.Config) WorkspaceStorage {
+	switch cfg.StorageBackend {
+	case config.StorageKV:
+		return NewKVWorkspaceStore(storage.NewFileKV(filepath.Join(cfg.StorageDir, "workspaces")))
+	default:
+		return NewWorkspaceStore()
+	}
+}
@@ -3,9 +3,12 @@ package workspace
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 )
 
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
 type WorkspaceStore struct {
 	mu         sync.RWMutex
 	workspaces map[string]*Workspace
@@ -23,7 +26,7 @@ func (s *WorkspaceStore) GetByID(id string) (*Workspace, error) {
 
 	ws, ok := s.workspaces[id]
 	if !ok {
-		return nil, errors.New("workspace not found")
+		return nil, ErrWorkspaceNotFound
 	}
 
 	return ws, nil
@@ -39,7 +42,7 @@ func (s *WorkspaceStore) GetByPath(path string) (*Workspace, error) {
 		}
 	}
 
-	return nil, errors.New("workspace not found")
+	return nil, ErrWorkspaceNotFound
 }
 
 func (s *WorkspaceStore) List() []Workspace {
@@ -51,6 +54,10 @@ func (s *WorkspaceStore) List() []Workspace {
 		workspaces = append(workspaces, *ws)
 	}
 
+	sort.Slice(workspaces, func(i, j int) bool {
+		return lessWorkspace(&workspaces[i], &workspaces[j])
+	})
+
 	return workspaces
 }
 
@@ -74,28 +81,23 @@ func (s *WorkspaceStore) Add(ws *Workspace) error {
 	return nil
 }
 
-func (s *WorkspaceStore) OnAppStart(ctx context.Context) error {
-	workspaces := []*Workspace{
-		{
-			ID:        "ws-1",
-			Name:      "utena",
-			Path:      "/Users/eleonora/dev/utena",
-			IsGitRepo: true,
-		},
-		{
-			ID:        "ws-2",
-			Name:      "example-project",
-			Path:      "/Users/eleonora/dev/example",
-			IsGitRepo: false,
-		},
+func (s *WorkspaceStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("workspace ID cannot be empty")
 	}
 
-	for _, ws := range workspaces {
-		if err := s.Add(ws); err != nil {
-			return err
-		}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.workspaces[id]; !exists {
+		return ErrWorkspaceNotFound
 	}
 
+	delete(s.workspaces, id)
+	return nil
+}
+
+func (s *WorkspaceStore) OnAppStart(ctx context.Context) error {
 	return nil
 }
 
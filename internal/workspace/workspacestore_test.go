@@ -148,6 +148,22 @@ func TestWorkspaceStore_List_Empty(t *testing.T) {
 	require.Empty(t, list)
 }
 
+func TestWorkspaceStore_Delete(t *testing.T) {
+	store := setupWorkspaceStore(t)
+
+	store.Add(&Workspace{ID: "ws-1", Name: "test", Path: "/path"})
+
+	err := store.Delete("ws-1")
+	require.NoError(t, err)
+
+	_, err = store.GetByID("ws-1")
+	require.Error(t, err)
+
+	err = store.Delete("ws-1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found")
+}
+
 func TestWorkspaceStore_ConcurrentAccess(t *testing.T) {
 	store := setupWorkspaceStore(t)
 
@@ -193,25 +209,22 @@ func TestWorkspaceStore_OnAppStart(t *testing.T) {
 	err := store.OnAppStart(ctx)
 	require.NoError(t, err)
 
-	// Verify workspaces were initialized
-	workspaces := store.List()
-	require.Len(t, workspaces, 2)
+	// Workspaces are populated by discovery in the service, not the store
+	require.Empty(t, store.List())
+}
 
-	// Check ws-1 (utena)
-	ws1, err := store.GetByID("ws-1")
-	require.NoError(t, err)
-	require.Equal(t, "ws-1", ws1.ID)
-	require.Equal(t, "utena", ws1.Name)
-	require.Equal(t, "/Users/eleonora/dev/utena", ws1.Path)
-	require.True(t, ws1.IsGitRepo)
+func TestWorkspaceStore_List_SortedByName(t *testing.T) {
+	store := setupWorkspaceStore(t)
 
-	// Check ws-2 (example-project)
-	ws2, err := store.GetByID("ws-2")
-	require.NoError(t, err)
-	require.Equal(t, "ws-2", ws2.ID)
-	require.Equal(t, "example-project", ws2.Name)
-	require.Equal(t, "/Users/eleonora/dev/example", ws2.Path)
-	require.False(t, ws2.IsGitRepo)
+	store.Add(&Workspace{ID: "ws-1", Name: "zeta", Path: "/zeta"})
+	store.Add(&Workspace{ID: "ws-2", Name: "Alpha", Path: "/alpha"})
+	store.Add(&Workspace{ID: "ws-3", Name: "beta", Path: "/beta"})
+
+	list := store.List()
+	require.Len(t, list, 3)
+	require.Equal(t, "Alpha", list[0].Name)
+	require.Equal(t, "beta", list[1].Name)
+	require.Equal(t, "zeta", list[2].Name)
 }
 
 func TestWorkspaceStore_OnAppEnd(t *testing.T) {
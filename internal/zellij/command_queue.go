@@ -2,36 +2,119 @@ package zellij
 
 import (
 	"sync"
+	"time"
 )
 
+// Command is sent to the plugin, which reports the outcome for ID to
+// POST /zellij/commands/{id}/result.
 type Command struct {
-	Command       string  `json:"command"`
+	ID      string `json:"id"`
+	Command string `json:"command"`
+	// Version is the protocol version the command needs the plugin to
+	// speak.
+	Version       int     `json:"version"`
 	SessionName   *string `json:"session_name,omitempty"`
 	WorkspacePath *string `json:"workspace_path,omitempty"`
 }
 
+type queuedCommand struct {
+	cmd         Command
+	deliveredAt time.Time
+}
+
+// CommandQueue holds commands until they are acknowledged. A dequeued
+// command stays in the queue in flight; if it isn't acked within ackTimeout
+// it is delivered again, ahead of newer commands. Delivery is therefore at
+// least once and in enqueue order.
 type CommandQueue struct {
 	mu       sync.Mutex
-	commands []Command
+	commands []*queuedCommand
+	// ready is closed and replaced whenever a command is enqueued.
+	ready chan struct{}
+
+	ackTimeout time.Duration
+	now        func() time.Time
 }
 
-func NewCommandQueue() *CommandQueue {
+func NewCommandQueue(ackTimeout time.Duration) *CommandQueue {
 	return &CommandQueue{
-		commands: make([]Command, 0),
+		commands:   make([]*queuedCommand, 0),
+		ready:      make(chan struct{}),
+		ackTimeout: ackTimeout,
+		now:        time.Now,
 	}
 }
 
 func (q *CommandQueue) Enqueue(cmd Command) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	q.commands = append(q.commands, cmd)
+
+	q.commands = append(q.commands, &queuedCommand{cmd: cmd})
+
+	close(q.ready)
+	q.ready = make(chan struct{})
 }
 
+// DequeueAll returns every command that is due for delivery and marks them in
+// flight.
 func (q *CommandQueue) DequeueAll() []Command {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	commands := q.commands
-	q.commands = make([]Command, 0) // Clear queue
+	now := q.now()
+	commands := make([]Command, 0)
+	for _, queued := range q.commands {
+		if !queued.deliveredAt.IsZero() && now.Sub(queued.deliveredAt) < q.ackTimeout {
+			continue
+		}
+
+		queued.deliveredAt = now
+		commands = append(commands, queued.cmd)
+	}
+
+	return commands
+}
+
+// Ack removes a delivered command. It reports whether the command was queued.
+func (q *CommandQueue) Ack(id string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, queued := range q.commands {
+		if queued.cmd.ID == id {
+			q.commands = append(q.commands[:i:i], q.commands[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Drain removes and returns every command still queued, delivered or not, in
+// enqueue order.
+func (q *CommandQueue) Drain() []Command {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	commands := make([]Command, len(q.commands))
+	for i, queued := range q.commands {
+		commands[i] = queued.cmd
+	}
+	q.commands = make([]*queuedCommand, 0)
+
 	return commands
 }
+
+func (q *CommandQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.commands)
+}
+
+// Ready is closed the next time a command is enqueued.
+func (q *CommandQueue) Ready() <-chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.ready
+}
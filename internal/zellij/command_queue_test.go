@@ -0,0 +1,87 @@
+package zellij
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupCommandQueue creates a queue with a settable clock
+func setupCommandQueue(t *testing.T) (*CommandQueue, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2026, 1, 27, 10, 0, 0, 0, time.UTC)
+	queue := NewCommandQueue(time.Minute)
+	queue.now = func() time.Time { return now }
+	return queue, &now
+}
+
+func commandIDs(commands []Command) []string {
+	ids := make([]string, len(commands))
+	for i, cmd := range commands {
+		ids[i] = cmd.ID
+	}
+	return ids
+}
+
+func TestCommandQueue_DeliversInOrder(t *testing.T) {
+	queue, _ := setupCommandQueue(t)
+
+	queue.Enqueue(testCommand("cmd-1"))
+	queue.Enqueue(testCommand("cmd-2"))
+	queue.Enqueue(testCommand("cmd-3"))
+
+	require.Equal(t, []string{"cmd-1", "cmd-2", "cmd-3"}, commandIDs(queue.DequeueAll()))
+}
+
+func TestCommandQueue_InFlightUntilAckTimeout(t *testing.T) {
+	queue, now := setupCommandQueue(t)
+
+	queue.Enqueue(testCommand("cmd-1"))
+	require.Equal(t, []string{"cmd-1"}, commandIDs(queue.DequeueAll()))
+
+	// Delivered commands aren't handed out again while in flight
+	queue.Enqueue(testCommand("cmd-2"))
+	require.Equal(t, []string{"cmd-2"}, commandIDs(queue.DequeueAll()))
+	require.Empty(t, queue.DequeueAll())
+
+	// Unacked commands come back, oldest first
+	*now = now.Add(time.Minute)
+	queue.Enqueue(testCommand("cmd-3"))
+	require.Equal(t, []string{"cmd-1", "cmd-2", "cmd-3"}, commandIDs(queue.DequeueAll()))
+}
+
+func TestCommandQueue_Ack(t *testing.T) {
+	queue, now := setupCommandQueue(t)
+
+	queue.Enqueue(testCommand("cmd-1"))
+	queue.Enqueue(testCommand("cmd-2"))
+	queue.DequeueAll()
+
+	require.True(t, queue.Ack("cmd-1"))
+	require.False(t, queue.Ack("cmd-1"))
+	require.Equal(t, 1, queue.Len())
+
+	// Only the unacked command is redelivered
+	*now = now.Add(time.Minute)
+	require.Equal(t, []string{"cmd-2"}, commandIDs(queue.DequeueAll()))
+}
+
+func TestCommandQueue_Ready(t *testing.T) {
+	queue, _ := setupCommandQueue(t)
+
+	ready := queue.Ready()
+	select {
+	case <-ready:
+		t.Fatal("ready before anything was enqueued")
+	default:
+	}
+
+	queue.Enqueue(testCommand("cmd-1"))
+	select {
+	case <-ready:
+	default:
+		t.Fatal("enqueue did not signal ready")
+	}
+}
@@ -0,0 +1,230 @@
+package zellij
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCommandTimeout   = 10 * time.Second
+	defaultCommandRetention = 256
+)
+
+var (
+	ErrCommandNotFound  = errors.New("command not found")
+	ErrCommandCompleted = errors.New("command already completed")
+)
+
+type CommandState string
+
+const (
+	CommandPending   CommandState = "pending"
+	CommandSucceeded CommandState = "succeeded"
+	CommandFailed    CommandState = "failed"
+	// CommandTimedOut means the plugin never reported back. The command may
+	// still have run.
+	CommandTimedOut CommandState = "timed_out"
+)
+
+// CommandStatus is what the daemon knows about a command sent to the plugin.
+type CommandStatus struct {
+	ID          string       `json:"id"`
+	Command     string       `json:"command"`
+	SessionName string       `json:"session_name,omitempty"`
+	State       CommandState `json:"state"`
+	Error       string       `json:"error,omitempty"`
+	SentAt      time.Time    `json:"sent_at"`
+	CompletedAt time.Time    `json:"completed_at,omitzero"`
+}
+
+func (s CommandStatus) IsDone() bool {
+	return s.State != CommandPending
+}
+
+type trackedCommand struct {
+	status CommandStatus
+	timer  *time.Timer
+}
+
+// CommandTracker follows commands from the moment they're sent until the
+// plugin reports their outcome or they time out. Finished commands are kept
+// for a while so callers can still poll them.
+type CommandTracker struct {
+	mu        sync.Mutex
+	commands  map[string]*trackedCommand
+	completed []string
+	// changed is closed and replaced whenever a command changes state.
+	changed chan struct{}
+
+	timeout   time.Duration
+	retention int
+	now       func() time.Time
+}
+
+func NewCommandTracker(timeout time.Duration) *CommandTracker {
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+
+	return &CommandTracker{
+		commands:  make(map[string]*trackedCommand),
+		changed:   make(chan struct{}),
+		timeout:   timeout,
+		retention: defaultCommandRetention,
+		now:       time.Now,
+	}
+}
+
+// Track marks cmd as pending. Tracking an ID again, e.g. when a failed send
+// is retried, restarts its timeout.
+func (t *CommandTracker) Track(cmd Command) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if existing, ok := t.commands[cmd.ID]; ok {
+		existing.timer.Stop()
+		t.forget(cmd.ID)
+	}
+
+	status := CommandStatus{
+		ID:      cmd.ID,
+		Command: cmd.Command,
+		State:   CommandPending,
+		SentAt:  t.now(),
+	}
+	if cmd.SessionName != nil {
+		status.SessionName = *cmd.SessionName
+	}
+
+	tracked := &trackedCommand{status: status}
+	tracked.timer = time.AfterFunc(t.timeout, func() {
+		t.expire(tracked)
+	})
+	t.commands[cmd.ID] = tracked
+	t.broadcast()
+}
+
+// Complete records the outcome of a command. A command that timed out can
+// still be completed by a late report; one that already has an outcome
+// cannot.
+func (t *CommandTracker) Complete(id string, cmdErr error) (CommandStatus, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.commands[id]
+	if !ok {
+		return CommandStatus{}, ErrCommandNotFound
+	}
+
+	switch tracked.status.State {
+	case CommandSucceeded, CommandFailed:
+		return tracked.status, ErrCommandCompleted
+	case CommandPending:
+		tracked.timer.Stop()
+		t.completed = append(t.completed, id)
+	}
+
+	tracked.status.State = CommandSucceeded
+	tracked.status.Error = ""
+	if cmdErr != nil {
+		tracked.status.State = CommandFailed
+		tracked.status.Error = cmdErr.Error()
+	}
+	tracked.status.CompletedAt = t.now()
+
+	t.prune()
+	t.broadcast()
+	return tracked.status, nil
+}
+
+func (t *CommandTracker) Get(id string) (CommandStatus, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.commands[id]
+	if !ok {
+		return CommandStatus{}, ErrCommandNotFound
+	}
+	return tracked.status, nil
+}
+
+// Wait blocks until the command is done or ctx ends, and returns its latest
+// status. It also waits for commands that aren't tracked yet, since the
+// command may still be queued on the event bus.
+func (t *CommandTracker) Wait(ctx context.Context, id string) (CommandStatus, error) {
+	for {
+		t.mu.Lock()
+		tracked, ok := t.commands[id]
+		var status CommandStatus
+		if ok {
+			status = tracked.status
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		if ok && status.IsDone() {
+			return status, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			if !ok {
+				return CommandStatus{}, ErrCommandNotFound
+			}
+			return status, nil
+		}
+	}
+}
+
+// Close stops the timeout timers.
+func (t *CommandTracker) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tracked := range t.commands {
+		tracked.timer.Stop()
+	}
+}
+
+func (t *CommandTracker) expire(tracked *trackedCommand) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The command may have been completed or re-tracked meanwhile
+	if t.commands[tracked.status.ID] != tracked || tracked.status.State != CommandPending {
+		return
+	}
+
+	tracked.status.State = CommandTimedOut
+	tracked.status.CompletedAt = t.now()
+	t.completed = append(t.completed, tracked.status.ID)
+
+	t.prune()
+	t.broadcast()
+}
+
+// prune drops the oldest finished commands beyond the retention limit.
+func (t *CommandTracker) prune() {
+	for len(t.completed) > t.retention {
+		delete(t.commands, t.completed[0])
+		t.completed = t.completed[1:]
+	}
+}
+
+func (t *CommandTracker) forget(id string) {
+	delete(t.commands, id)
+	for i, completed := range t.completed {
+		if completed == id {
+			t.completed = append(t.completed[:i:i], t.completed[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *CommandTracker) broadcast() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
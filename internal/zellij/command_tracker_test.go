@@ -0,0 +1,141 @@
+package zellij
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setupCommandTracker creates a tracker whose timers are stopped after the test
+func setupCommandTracker(t *testing.T, timeout time.Duration) *CommandTracker {
+	t.Helper()
+
+	tracker := NewCommandTracker(timeout)
+	t.Cleanup(tracker.Close)
+	return tracker
+}
+
+func testCommand(id string) Command {
+	cmd := switchSessionCommand("utena")
+	cmd.ID = id
+	return cmd
+}
+
+func TestCommandTracker_Complete(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+	tracker.Track(testCommand("cmd-1"))
+
+	status, err := tracker.Get("cmd-1")
+	require.NoError(t, err)
+	require.Equal(t, CommandPending, status.State)
+	require.Equal(t, "utena", status.SessionName)
+
+	status, err = tracker.Complete("cmd-1", nil)
+	require.NoError(t, err)
+	require.Equal(t, CommandSucceeded, status.State)
+	require.False(t, status.CompletedAt.IsZero())
+
+	// An outcome can only be reported once
+	_, err = tracker.Complete("cmd-1", errors.New("session not found"))
+	require.ErrorIs(t, err, ErrCommandCompleted)
+}
+
+func TestCommandTracker_Complete_Failed(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+	tracker.Track(testCommand("cmd-1"))
+
+	status, err := tracker.Complete("cmd-1", errors.New("session not found"))
+	require.NoError(t, err)
+	require.Equal(t, CommandFailed, status.State)
+	require.Equal(t, "session not found", status.Error)
+}
+
+func TestCommandTracker_Complete_NotFound(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+
+	_, err := tracker.Complete("nonexistent", nil)
+	require.ErrorIs(t, err, ErrCommandNotFound)
+}
+
+func TestCommandTracker_Timeout(t *testing.T) {
+	tracker := setupCommandTracker(t, 10*time.Millisecond)
+	tracker.Track(testCommand("cmd-1"))
+
+	status, err := tracker.Wait(context.Background(), "cmd-1")
+	require.NoError(t, err)
+	require.Equal(t, CommandTimedOut, status.State)
+
+	// A late report still records the real outcome
+	status, err = tracker.Complete("cmd-1", nil)
+	require.NoError(t, err)
+	require.Equal(t, CommandSucceeded, status.State)
+}
+
+func TestCommandTracker_Track_RestartsTimeout(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+	tracker.Track(testCommand("cmd-1"))
+	tracker.Complete("cmd-1", errors.New("zellij pipe failed"))
+
+	// A retried send tracks the same ID again
+	tracker.Track(testCommand("cmd-1"))
+
+	status, err := tracker.Get("cmd-1")
+	require.NoError(t, err)
+	require.Equal(t, CommandPending, status.State)
+	require.Empty(t, status.Error)
+}
+
+func TestCommandTracker_Wait(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+
+	done := make(chan CommandStatus)
+	go func() {
+		// The command isn't tracked yet, as if still queued on the bus
+		status, _ := tracker.Wait(context.Background(), "cmd-1")
+		done <- status
+	}()
+
+	tracker.Track(testCommand("cmd-1"))
+	tracker.Complete("cmd-1", nil)
+
+	select {
+	case status := <-done:
+		require.Equal(t, CommandSucceeded, status.State)
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after the command completed")
+	}
+}
+
+func TestCommandTracker_Wait_ContextDone(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+	tracker.Track(testCommand("cmd-1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	status, err := tracker.Wait(ctx, "cmd-1")
+	require.NoError(t, err)
+	require.Equal(t, CommandPending, status.State)
+
+	_, err = tracker.Wait(ctx, "nonexistent")
+	require.ErrorIs(t, err, ErrCommandNotFound)
+}
+
+func TestCommandTracker_Retention(t *testing.T) {
+	tracker := setupCommandTracker(t, time.Minute)
+	tracker.retention = 2
+
+	for _, id := range []string{"cmd-1", "cmd-2", "cmd-3"} {
+		tracker.Track(testCommand(id))
+		tracker.Complete(id, nil)
+	}
+
+	_, err := tracker.Get("cmd-1")
+	require.ErrorIs(t, err, ErrCommandNotFound)
+
+	_, err = tracker.Get("cmd-3")
+	require.NoError(t, err)
+}
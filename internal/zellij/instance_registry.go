@@ -0,0 +1,177 @@
+package zellij
+
+import (
+	"crypto/sha256"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PluginInstance is one loaded copy of the plugin. Zellij loads the plugin in
+// every session, and each copy reports the same sessions.
+type PluginInstance struct {
+	ID string `json:"id"`
+	// HostSession is the session the instance runs in, the one it reports
+	// as current.
+	HostSession string    `json:"host_session"`
+	Version     string    `json:"version,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	// ProtocolVersion and Commands are negotiated when the instance
+	// registers. ProtocolVersion is 0 for instances that never did.
+	ProtocolVersion int      `json:"protocol_version,omitempty"`
+	Commands        []string `json:"commands,omitempty"`
+}
+
+type registeredInstance struct {
+	PluginInstance
+	lastReport [sha256.Size]byte
+}
+
+// InstanceRegistry tracks the plugin instances that have reported to the
+// daemon.
+type InstanceRegistry struct {
+	mu        sync.RWMutex
+	instances map[string]*registeredInstance
+	now       func() time.Time
+}
+
+func NewInstanceRegistry() *InstanceRegistry {
+	return &InstanceRegistry{
+		instances: make(map[string]*registeredInstance),
+		now:       time.Now,
+	}
+}
+
+// Report records a session report from an instance. It returns false when
+// the report repeats the instance's previous one, which makes it redundant.
+func (r *InstanceRegistry) Report(id, hostSession, version string, report [sha256.Size]byte) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance, existed := r.upsert(id, hostSession, version)
+	if existed && instance.lastReport == report {
+		return false
+	}
+	instance.lastReport = report
+	return true
+}
+
+// Register records the protocol an instance negotiated and the commands it
+// can execute.
+func (r *InstanceRegistry) Register(id, hostSession, version string, protocolVersion int, commands []string) PluginInstance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance, _ := r.upsert(id, hostSession, version)
+	instance.ProtocolVersion = protocolVersion
+	instance.Commands = slices.Clone(commands)
+	return instance.PluginInstance
+}
+
+// Heartbeat records that an instance is still running.
+func (r *InstanceRegistry) Heartbeat(id, hostSession, version string) PluginInstance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	instance, _ := r.upsert(id, hostSession, version)
+	return instance.PluginInstance
+}
+
+// upsert marks the instance as seen now. Callers hold mu.
+func (r *InstanceRegistry) upsert(id, hostSession, version string) (*registeredInstance, bool) {
+	now := r.now()
+	instance, existed := r.instances[id]
+	if !existed {
+		instance = &registeredInstance{PluginInstance: PluginInstance{ID: id, FirstSeenAt: now}}
+		r.instances[id] = instance
+	}
+
+	instance.LastSeenAt = now
+	if hostSession != "" {
+		instance.HostSession = hostSession
+	}
+	if version != "" {
+		instance.Version = version
+	}
+	return instance, existed
+}
+
+// Invalidate makes every instance's next report count as new, e.g. after the
+// sessions were changed by something other than a plugin.
+func (r *InstanceRegistry) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, instance := range r.instances {
+		instance.lastReport = [sha256.Size]byte{}
+	}
+}
+
+// Get returns the instance with the given ID.
+func (r *InstanceRegistry) Get(id string) (PluginInstance, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instance, ok := r.instances[id]
+	if !ok {
+		return PluginInstance{}, false
+	}
+	return instance.PluginInstance, true
+}
+
+// ForSession returns the most recently seen instance running in a session.
+func (r *InstanceRegistry) ForSession(sessionName string) (PluginInstance, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		found PluginInstance
+		ok    bool
+	)
+	for _, instance := range r.instances {
+		if instance.HostSession != sessionName {
+			continue
+		}
+		if !ok || instance.LastSeenAt.After(found.LastSeenAt) {
+			found, ok = instance.PluginInstance, true
+		}
+	}
+	return found, ok
+}
+
+// List returns all instances, most recently seen first.
+func (r *InstanceRegistry) List() []PluginInstance {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	instances := make([]PluginInstance, 0, len(r.instances))
+	for _, instance := range r.instances {
+		instances = append(instances, instance.PluginInstance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		if !instances[i].LastSeenAt.Equal(instances[j].LastSeenAt) {
+			return instances[i].LastSeenAt.After(instances[j].LastSeenAt)
+		}
+		return instances[i].ID < instances[j].ID
+	})
+	return instances
+}
+
+// Retain forgets instances hosted in sessions that are no longer running and
+// returns their IDs. Instances whose host isn't known yet are kept.
+func (r *InstanceRegistry) Retain(runningSessions map[string]bool) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var forgotten []string
+	for id, instance := range r.instances {
+		if instance.HostSession != "" && !runningSessions[instance.HostSession] {
+			delete(r.instances, id)
+			forgotten = append(forgotten, id)
+		}
+	}
+	return forgotten
+}
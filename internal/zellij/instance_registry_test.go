@@ -0,0 +1,118 @@
+package zellij
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func setupInstanceRegistry(t *testing.T) (*InstanceRegistry, *time.Time) {
+	t.Helper()
+
+	now := time.Date(2026, 1, 27, 12, 0, 0, 0, time.UTC)
+	registry := NewInstanceRegistry()
+	registry.now = func() time.Time { return now }
+	return registry, &now
+}
+
+func report(s string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(s))
+}
+
+func TestInstanceRegistry_Report(t *testing.T) {
+	registry, now := setupInstanceRegistry(t)
+	firstSeen := *now
+
+	require.True(t, registry.Report("plugin-1", "utena-main", "0.1.0", report("a")))
+
+	// The same report again is redundant but still counts as a sighting
+	*now = now.Add(time.Minute)
+	require.False(t, registry.Report("plugin-1", "utena-main", "", report("a")))
+	require.True(t, registry.Report("plugin-1", "utena-main", "", report("b")))
+
+	instance, ok := registry.Get("plugin-1")
+	require.True(t, ok)
+	require.Equal(t, PluginInstance{
+		ID:          "plugin-1",
+		HostSession: "utena-main",
+		Version:     "0.1.0",
+		FirstSeenAt: firstSeen,
+		LastSeenAt:  *now,
+	}, instance)
+
+	// Another instance sending the same report isn't redundant
+	require.True(t, registry.Report("plugin-2", "other", "0.1.0", report("b")))
+}
+
+func TestInstanceRegistry_Invalidate(t *testing.T) {
+	registry, _ := setupInstanceRegistry(t)
+
+	require.True(t, registry.Report("plugin-1", "utena-main", "", report("a")))
+	registry.Invalidate()
+	require.True(t, registry.Report("plugin-1", "utena-main", "", report("a")))
+}
+
+func TestInstanceRegistry_ListAndForSession(t *testing.T) {
+	registry, now := setupInstanceRegistry(t)
+
+	registry.Report("plugin-1", "utena-main", "", report("a"))
+	*now = now.Add(time.Second)
+	registry.Report("plugin-2", "other", "", report("a"))
+	*now = now.Add(time.Second)
+	registry.Report("plugin-3", "utena-main", "", report("a"))
+
+	instances := registry.List()
+	require.Len(t, instances, 3)
+	require.Equal(t, "plugin-3", instances[0].ID)
+	require.Equal(t, "plugin-1", instances[2].ID)
+
+	instance, ok := registry.ForSession("utena-main")
+	require.True(t, ok)
+	require.Equal(t, "plugin-3", instance.ID)
+
+	_, ok = registry.ForSession("missing")
+	require.False(t, ok)
+}
+
+func TestInstanceRegistry_Retain(t *testing.T) {
+	registry, _ := setupInstanceRegistry(t)
+
+	registry.Report("plugin-1", "utena-main", "", report("a"))
+	registry.Report("plugin-2", "other", "", report("a"))
+	registry.Report("plugin-3", "", "", report("a"))
+
+	forgotten := registry.Retain(map[string]bool{"utena-main": true})
+	require.Equal(t, []string{"plugin-2"}, forgotten)
+
+	instances := registry.List()
+	require.Len(t, instances, 2)
+	_, ok := registry.Get("plugin-2")
+	require.False(t, ok)
+}
+
+func TestInstanceRegistry_Register(t *testing.T) {
+	registry, now := setupInstanceRegistry(t)
+
+	commands := []string{"open_picker", "switch_session"}
+	instance := registry.Register("plugin-1", "utena-main", "0.1.0", 1, commands)
+	commands[0] = "close_picker"
+
+	require.Equal(t, PluginInstance{
+		ID:              "plugin-1",
+		HostSession:     "utena-main",
+		Version:         "0.1.0",
+		FirstSeenAt:     *now,
+		LastSeenAt:      *now,
+		ProtocolVersion: 1,
+		Commands:        []string{"open_picker", "switch_session"},
+	}, instance)
+
+	// Later reports keep what the handshake negotiated
+	registry.Report("plugin-1", "utena-main", "", report("a"))
+	instance, ok := registry.Get("plugin-1")
+	require.True(t, ok)
+	require.Equal(t, 1, instance.ProtocolVersion)
+	require.Equal(t, []string{"open_picker", "switch_session"}, instance.Commands)
+}
@@ -0,0 +1,176 @@
+package zellij
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+)
+
+const defaultHeartbeatInterval = 10 * time.Second
+
+// Connectivity is how recently the daemon heard from any plugin instance.
+type Connectivity string
+
+const (
+	// Connected means a plugin checked in within two heartbeat intervals.
+	Connected Connectivity = "connected"
+	// Degraded means heartbeats were missed, but not yet enough to give up.
+	Degraded Connectivity = "degraded"
+	// Disconnected means no plugin has checked in for six heartbeat
+	// intervals, or ever. Session state may be stale.
+	Disconnected Connectivity = "disconnected"
+)
+
+const (
+	degradedAfterBeats     = 2
+	disconnectedAfterBeats = 6
+)
+
+// ConnectivityStatus is a point-in-time view of the plugin integration.
+type ConnectivityStatus struct {
+	Status Connectivity `json:"status"`
+	// LastSeenAt is nil until a plugin has checked in.
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// Since is when the integration entered Status.
+	Since time.Time `json:"since"`
+}
+
+// LivenessTracker derives the plugin connectivity from heartbeats and session
+// reports, publishing an event on every change.
+type LivenessTracker struct {
+	mu       sync.Mutex
+	bus      eventbus.EventBus
+	interval time.Duration
+	status   Connectivity
+	since    time.Time
+	lastSeen time.Time
+	now      func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewLivenessTracker(bus eventbus.EventBus, interval time.Duration) *LivenessTracker {
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+
+	return &LivenessTracker{
+		bus:      bus,
+		interval: interval,
+		status:   Disconnected,
+		since:    time.Now(),
+		now:      time.Now,
+	}
+}
+
+func (l *LivenessTracker) OnAppStart(ctx context.Context) error {
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go l.run()
+
+	return nil
+}
+
+// OnAppEnd stops the checks. Shutdown also runs when a module failed to
+// start, so a tracker that was never started is left alone.
+func (l *LivenessTracker) OnAppEnd(ctx context.Context) error {
+	if l.stop == nil {
+		return nil
+	}
+
+	close(l.stop)
+	<-l.done
+	l.stop = nil
+
+	return nil
+}
+
+func (l *LivenessTracker) run() {
+	defer close(l.done)
+
+	// Checking at half the interval notices a missed heartbeat promptly
+	ticker := time.NewTicker(l.interval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.Check(context.Background())
+		}
+	}
+}
+
+// Seen records contact from a plugin instance.
+func (l *LivenessTracker) Seen(ctx context.Context) {
+	l.mu.Lock()
+	l.lastSeen = l.now()
+	event, changed := l.evaluate()
+	l.mu.Unlock()
+
+	if changed {
+		l.publish(ctx, event)
+	}
+}
+
+// Check re-evaluates connectivity as time passes without contact.
+func (l *LivenessTracker) Check(ctx context.Context) {
+	l.mu.Lock()
+	event, changed := l.evaluate()
+	l.mu.Unlock()
+
+	if changed {
+		l.publish(ctx, event)
+	}
+}
+
+func (l *LivenessTracker) Status() ConnectivityStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := ConnectivityStatus{Status: l.status, Since: l.since}
+	if !l.lastSeen.IsZero() {
+		lastSeen := l.lastSeen
+		status.LastSeenAt = &lastSeen
+	}
+	return status
+}
+
+// evaluate updates the status for the current time. Callers hold mu.
+func (l *LivenessTracker) evaluate() (eventbus.ZellijConnectivityChangedEvent, bool) {
+	now := l.now()
+
+	next := Disconnected
+	if !l.lastSeen.IsZero() {
+		silence := now.Sub(l.lastSeen)
+		switch {
+		case silence < degradedAfterBeats*l.interval:
+			next = Connected
+		case silence < disconnectedAfterBeats*l.interval:
+			next = Degraded
+		}
+	}
+
+	if next == l.status {
+		return eventbus.ZellijConnectivityChangedEvent{}, false
+	}
+
+	event := eventbus.ZellijConnectivityChangedEvent{
+		From:       string(l.status),
+		To:         string(next),
+		LastSeenAt: l.lastSeen,
+	}
+	l.status, l.since = next, now
+	return event, true
+}
+
+func (l *LivenessTracker) publish(ctx context.Context, event eventbus.ZellijConnectivityChangedEvent) {
+	if err := eventbus.Publish(ctx, l.bus, event); err != nil {
+		log.Printf("Failed to publish %T: %v", event, err)
+	}
+}
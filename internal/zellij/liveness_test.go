@@ -0,0 +1,92 @@
+package zellij
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/eventbus"
+	"github.com/stretchr/testify/require"
+)
+
+func setupLivenessTracker(t *testing.T) (*LivenessTracker, *time.Time, *[]eventbus.ZellijConnectivityChangedEvent) {
+	t.Helper()
+
+	bus := eventbus.NewEventBus()
+	var events []eventbus.ZellijConnectivityChangedEvent
+	eventbus.Subscribe(bus, func(ctx context.Context, event eventbus.ZellijConnectivityChangedEvent) error {
+		events = append(events, event)
+		return nil
+	})
+
+	now := time.Date(2026, 1, 27, 12, 0, 0, 0, time.UTC)
+	tracker := NewLivenessTracker(bus, 10*time.Second)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now, &events
+}
+
+func TestLivenessTracker_Transitions(t *testing.T) {
+	tracker, now, events := setupLivenessTracker(t)
+	ctx := context.Background()
+
+	require.Equal(t, Disconnected, tracker.Status().Status)
+	require.Nil(t, tracker.Status().LastSeenAt)
+
+	tracker.Seen(ctx)
+	seenAt := *now
+	require.Equal(t, Connected, tracker.Status().Status)
+	require.Equal(t, &seenAt, tracker.Status().LastSeenAt)
+
+	// One missed heartbeat is tolerated
+	*now = now.Add(15 * time.Second)
+	tracker.Check(ctx)
+	require.Equal(t, Connected, tracker.Status().Status)
+
+	*now = now.Add(10 * time.Second)
+	tracker.Check(ctx)
+	require.Equal(t, Degraded, tracker.Status().Status)
+	require.Equal(t, *now, tracker.Status().Since)
+
+	*now = now.Add(time.Minute)
+	tracker.Check(ctx)
+	require.Equal(t, Disconnected, tracker.Status().Status)
+
+	tracker.Seen(ctx)
+	require.Equal(t, Connected, tracker.Status().Status)
+
+	require.Equal(t, []eventbus.ZellijConnectivityChangedEvent{
+		{From: "disconnected", To: "connected", LastSeenAt: seenAt},
+		{From: "connected", To: "degraded", LastSeenAt: seenAt},
+		{From: "degraded", To: "disconnected", LastSeenAt: seenAt},
+		{From: "disconnected", To: "connected", LastSeenAt: *now},
+	}, *events)
+}
+
+func TestLivenessTracker_RepeatedContactPublishesOnce(t *testing.T) {
+	tracker, now, events := setupLivenessTracker(t)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		tracker.Seen(ctx)
+		*now = now.Add(10 * time.Second)
+		tracker.Check(ctx)
+	}
+
+	require.Len(t, *events, 1)
+}
+
+func TestLivenessTracker_StartStop(t *testing.T) {
+	tracker, _, _ := setupLivenessTracker(t)
+	ctx := context.Background()
+
+	require.NoError(t, tracker.OnAppStart(ctx))
+	require.NoError(t, tracker.OnAppEnd(ctx))
+}
+
+func TestLivenessTracker_StopWithoutStart(t *testing.T) {
+	tracker, _, _ := setupLivenessTracker(t)
+
+	require.NotPanics(t, func() {
+		require.NoError(t, tracker.OnAppEnd(context.Background()))
+	})
+}
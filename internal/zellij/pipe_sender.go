@@ -1,37 +1,79 @@
 package zellij
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"time"
 )
 
+const defaultPipeTimeout = 5 * time.Second
+
+// CommandSender delivers commands to the plugin.
+type CommandSender interface {
+	SendCommand(ctx context.Context, cmd Command) error
+}
+
+// TargetedSender is a CommandSender that can also deliver a command to one
+// plugin instance instead of whichever one it happens to reach.
+type TargetedSender interface {
+	CommandSender
+	SendCommandTo(ctx context.Context, instance PluginInstance, cmd Command) error
+}
+
+// PipeSender runs `zellij pipe` for every command.
 type PipeSender struct {
 	pipeName string
+	timeout  time.Duration
 }
 
-func NewPipeSender() *PipeSender {
+func NewPipeSender(timeout time.Duration) *PipeSender {
+	if timeout <= 0 {
+		timeout = defaultPipeTimeout
+	}
+
 	return &PipeSender{
 		pipeName: "utena-commands",
+		timeout:  timeout,
 	}
 }
 
-func (p *PipeSender) SendCommand(cmd Command) error {
+// SendCommand pipes cmd to the session zellij picks, the one named by
+// ZELLIJ_SESSION_NAME when the daemon runs inside Zellij.
+func (p *PipeSender) SendCommand(ctx context.Context, cmd Command) error {
+	return p.pipe(ctx, nil, cmd)
+}
+
+// SendCommandTo pipes cmd to the plugin instance in the instance's host
+// session.
+func (p *PipeSender) SendCommandTo(ctx context.Context, instance PluginInstance, cmd Command) error {
+	if instance.HostSession == "" {
+		return p.SendCommand(ctx, cmd)
+	}
+	return p.pipe(ctx, []string{"--session", instance.HostSession}, cmd)
+}
+
+// pipe kills `zellij pipe` if it runs past the timeout or ctx ends; it
+// otherwise blocks for as long as no plugin reads the pipe.
+func (p *PipeSender) pipe(ctx context.Context, globalArgs []string, cmd Command) error {
 	// Serialize command to JSON
 	payload, err := json.Marshal(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to marshal command: %w", err)
 	}
 
-	// Execute: zellij pipe --name utena-commands --payload '<json>'
-	shellCmd := exec.Command(
-		"zellij",
-		"pipe",
-		"--name", p.pipeName,
-		"--payload", string(payload),
-	)
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	// Execute: zellij [--session <name>] pipe --name utena-commands --payload '<json>'
+	args := append(globalArgs, "pipe", "--name", p.pipeName, "--payload", string(payload))
+	shellCmd := exec.CommandContext(ctx, "zellij", args...)
 
 	output, err := shellCmd.CombinedOutput()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("zellij pipe failed: %w", ctxErr)
+	}
 	if err != nil {
 		return fmt.Errorf("zellij pipe failed: %w, output: %s", err, output)
 	}
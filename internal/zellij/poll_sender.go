@@ -0,0 +1,149 @@
+package zellij
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultAckTimeout = 30 * time.Second
+
+// PollSender delivers commands to plugins that fetch them with
+// GET /zellij/commands instead of through `zellij pipe`. Commands for a
+// specific instance wait in that instance's queue; the rest wait in a shared
+// queue that whichever instance polls first receives.
+//
+// Instance queues only come from targeted sends, which go to instances the
+// InstanceRegistry knows. Polling never creates one, so arbitrary instance
+// IDs can't grow the map.
+type PollSender struct {
+	mu     sync.Mutex
+	queues map[string]*CommandQueue
+	shared *CommandQueue
+	// added is closed and replaced whenever an instance queue is created,
+	// waking pollers that had none.
+	added chan struct{}
+
+	ackTimeout time.Duration
+}
+
+func NewPollSender(ackTimeout time.Duration) *PollSender {
+	if ackTimeout <= 0 {
+		ackTimeout = defaultAckTimeout
+	}
+
+	return &PollSender{
+		queues:     make(map[string]*CommandQueue),
+		shared:     NewCommandQueue(ackTimeout),
+		added:      make(chan struct{}),
+		ackTimeout: ackTimeout,
+	}
+}
+
+// SendCommand queues cmd for the next instance that polls.
+func (p *PollSender) SendCommand(ctx context.Context, cmd Command) error {
+	p.shared.Enqueue(cmd)
+	return nil
+}
+
+// SendCommandTo queues cmd for one plugin instance.
+func (p *PollSender) SendCommandTo(ctx context.Context, instance PluginInstance, cmd Command) error {
+	p.queue(instance.ID).Enqueue(cmd)
+	return nil
+}
+
+// Poll returns the commands due for instance, waiting until there are some
+// or ctx ends. Returned commands are redelivered unless acked in time.
+func (p *PollSender) Poll(ctx context.Context, instance string) []Command {
+	for {
+		// The queue is looked up afresh each time: Forget may have dropped
+		// it, and a later targeted send may have created a new one.
+		// Grab the ready channels first so an enqueue between the dequeue
+		// and the wait isn't missed.
+		own, added := p.lookup(instance)
+		sharedReady := p.shared.Ready()
+		var ownReady <-chan struct{}
+		commands := make([]Command, 0)
+		if own != nil {
+			ownReady = own.Ready()
+			commands = append(commands, own.DequeueAll()...)
+		}
+		commands = append(commands, p.shared.DequeueAll()...)
+		if len(commands) > 0 {
+			return commands
+		}
+
+		// Unacked commands become due again without an enqueue
+		recheck := time.NewTimer(p.ackTimeout)
+		select {
+		case <-ownReady:
+		case <-sharedReady:
+		case <-added:
+		case <-recheck.C:
+		case <-ctx.Done():
+			recheck.Stop()
+			return commands
+		}
+		recheck.Stop()
+	}
+}
+
+// Ack confirms delivery of a command. It reports whether the command was
+// queued.
+func (p *PollSender) Ack(id string) bool {
+	if p.shared.Ack(id) {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, queue := range p.queues {
+		if queue.Ack(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Forget drops instance's queue. Commands it hadn't acked move to the shared
+// queue, so another instance carries them out instead of them waiting for a
+// plugin that is gone.
+func (p *PollSender) Forget(instance string) {
+	p.mu.Lock()
+	queue, ok := p.queues[instance]
+	delete(p.queues, instance)
+	p.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	for _, cmd := range queue.Drain() {
+		p.shared.Enqueue(cmd)
+	}
+}
+
+// lookup returns instance's queue, or nil if it has none, together with the
+// channel closed when the next queue is created.
+func (p *PollSender) lookup(instance string) (*CommandQueue, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.queues[instance], p.added
+}
+
+// queue returns instance's queue, creating it for a targeted send.
+func (p *PollSender) queue(instance string) *CommandQueue {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	queue, ok := p.queues[instance]
+	if !ok {
+		queue = NewCommandQueue(p.ackTimeout)
+		p.queues[instance] = queue
+
+		close(p.added)
+		p.added = make(chan struct{})
+	}
+	return queue
+}
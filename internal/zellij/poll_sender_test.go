@@ -0,0 +1,133 @@
+package zellij
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func pollWithin(t *testing.T, sender *PollSender, instance string, timeout time.Duration) []Command {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return sender.Poll(ctx, instance)
+}
+
+func TestPollSender_ReturnsQueuedCommands(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	require.NoError(t, sender.SendCommand(context.Background(), testCommand("cmd-1")))
+	require.NoError(t, sender.SendCommand(context.Background(), testCommand("cmd-2")))
+
+	commands := pollWithin(t, sender, "plugin-1", time.Second)
+	require.Equal(t, []string{"cmd-1", "cmd-2"}, commandIDs(commands))
+}
+
+func TestPollSender_WaitsForCommands(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	done := make(chan []Command)
+	go func() {
+		done <- pollWithin(t, sender, "plugin-1", time.Second)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, sender.SendCommand(context.Background(), testCommand("cmd-1")))
+
+	select {
+	case commands := <-done:
+		require.Equal(t, []string{"cmd-1"}, commandIDs(commands))
+	case <-time.After(time.Second):
+		t.Fatal("poll did not return after a command was sent")
+	}
+}
+
+func TestPollSender_TimesOutEmpty(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	commands := pollWithin(t, sender, "plugin-1", 10*time.Millisecond)
+	require.Empty(t, commands)
+	require.NotNil(t, commands)
+}
+
+func TestPollSender_TargetsInstance(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	require.NoError(t, sender.SendCommandTo(context.Background(), PluginInstance{ID: "plugin-2"}, testCommand("cmd-1")))
+	require.NoError(t, sender.SendCommand(context.Background(), testCommand("cmd-2")))
+
+	// plugin-1 only gets the shared command
+	require.Equal(t, []string{"cmd-2"}, commandIDs(pollWithin(t, sender, "plugin-1", time.Second)))
+	require.Equal(t, []string{"cmd-1"}, commandIDs(pollWithin(t, sender, "plugin-2", time.Second)))
+}
+
+func TestPollSender_ForgetMovesCommandsToShared(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	require.NoError(t, sender.SendCommandTo(context.Background(), PluginInstance{ID: "plugin-1"}, testCommand("cmd-1")))
+	require.NoError(t, sender.SendCommandTo(context.Background(), PluginInstance{ID: "plugin-1"}, testCommand("cmd-2")))
+
+	// plugin-1 received cmd-1 but went away before acking it
+	require.Equal(t, []string{"cmd-1", "cmd-2"}, commandIDs(pollWithin(t, sender, "plugin-1", time.Second)))
+	sender.Forget("plugin-1")
+
+	require.Equal(t, []string{"cmd-1", "cmd-2"}, commandIDs(pollWithin(t, sender, "plugin-2", time.Second)))
+	require.NotContains(t, sender.queues, "plugin-1")
+
+	// Forgetting an instance without a queue is harmless
+	sender.Forget("plugin-3")
+}
+
+func TestPollSender_PollingCreatesNoQueue(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+
+	require.Empty(t, pollWithin(t, sender, "stranger", 10*time.Millisecond))
+	require.Empty(t, sender.queues)
+}
+
+func TestPollSender_BlockedPollSeesQueueRecreatedAfterForget(t *testing.T) {
+	sender := NewPollSender(time.Minute)
+	instance := PluginInstance{ID: "plugin-1"}
+
+	require.NoError(t, sender.SendCommandTo(context.Background(), instance, testCommand("cmd-1")))
+	require.Equal(t, []string{"cmd-1"}, commandIDs(pollWithin(t, sender, "plugin-1", time.Second)))
+	require.True(t, sender.Ack("cmd-1"))
+
+	done := make(chan []Command)
+	go func() {
+		done <- pollWithin(t, sender, "plugin-1", time.Second)
+	}()
+
+	// The instance went away and came back while its poll was waiting
+	time.Sleep(10 * time.Millisecond)
+	sender.Forget("plugin-1")
+	require.NoError(t, sender.SendCommandTo(context.Background(), instance, testCommand("cmd-2")))
+
+	select {
+	case commands := <-done:
+		require.Equal(t, []string{"cmd-2"}, commandIDs(commands))
+	case <-time.After(2 * time.Second):
+		t.Fatal("poll did not return the command queued after Forget")
+	}
+}
+
+func TestPollSender_RedeliversUntilAcked(t *testing.T) {
+	sender := NewPollSender(20 * time.Millisecond)
+
+	require.NoError(t, sender.SendCommandTo(context.Background(), PluginInstance{ID: "plugin-1"}, testCommand("cmd-1")))
+	require.NoError(t, sender.SendCommand(context.Background(), testCommand("cmd-2")))
+	require.Equal(t, []string{"cmd-1", "cmd-2"}, commandIDs(pollWithin(t, sender, "plugin-1", time.Second)))
+
+	require.True(t, sender.Ack("cmd-1"))
+
+	// The plugin never acked cmd-2, so another instance receives it once
+	// the ack timeout passes
+	require.Equal(t, []string{"cmd-2"}, commandIDs(pollWithin(t, sender, "plugin-2", time.Second)))
+
+	require.True(t, sender.Ack("cmd-2"))
+	require.False(t, sender.Ack("cmd-2"))
+	require.Empty(t, pollWithin(t, sender, "plugin-1", 50*time.Millisecond))
+}
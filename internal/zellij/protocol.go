@@ -0,0 +1,53 @@
+package zellij
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// ProtocolVersion is the version of the JSON exchanged with the plugin
+	// over /zellij and the utena-commands pipe. Bump it whenever a shape
+	// changes in a way an older plugin would misread.
+	ProtocolVersion = 1
+	// MinProtocolVersion is the oldest plugin protocol the daemon still
+	// speaks.
+	MinProtocolVersion = 1
+
+	// legacyProtocolVersion is assumed for instances that never registered,
+	// which predate the handshake.
+	legacyProtocolVersion = 1
+)
+
+var (
+	ErrIncompatibleProtocol = errors.New("incompatible plugin protocol version")
+	ErrUnsupportedCommand   = errors.New("plugin cannot execute command")
+)
+
+// commandVersions is the protocol version each command was introduced in.
+var commandVersions = map[string]int{
+	"open_picker":    1,
+	"switch_session": 1,
+	"create_session": 1,
+	"close_picker":   1,
+}
+
+// negotiateProtocol picks the version to speak with a plugin declaring
+// pluginVersion. A newer plugin is spoken to at the daemon's version.
+func negotiateProtocol(pluginVersion int) (int, error) {
+	if pluginVersion < MinProtocolVersion {
+		return 0, fmt.Errorf("%w: plugin speaks %d, daemon needs %d to %d", ErrIncompatibleProtocol, pluginVersion, MinProtocolVersion, ProtocolVersion)
+	}
+	return min(pluginVersion, ProtocolVersion), nil
+}
+
+// Supports reports whether the instance can execute the command. Instances
+// that never registered are assumed to run every command of the legacy
+// protocol.
+func (i PluginInstance) Supports(cmd Command) bool {
+	if i.ProtocolVersion == 0 {
+		return cmd.Version <= legacyProtocolVersion
+	}
+	return cmd.Version <= i.ProtocolVersion && slices.Contains(i.Commands, cmd.Command)
+}
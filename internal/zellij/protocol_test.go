@@ -0,0 +1,39 @@
+package zellij
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateProtocol(t *testing.T) {
+	version, err := negotiateProtocol(ProtocolVersion)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolVersion, version)
+
+	// A newer plugin is expected to fall back to the daemon's version
+	version, err = negotiateProtocol(ProtocolVersion + 1)
+	require.NoError(t, err)
+	require.Equal(t, ProtocolVersion, version)
+
+	_, err = negotiateProtocol(MinProtocolVersion - 1)
+	require.ErrorIs(t, err, ErrIncompatibleProtocol)
+}
+
+func TestPluginInstance_Supports(t *testing.T) {
+	switchSession := switchSessionCommand("other")
+	switchSession.Version = 1
+	newer := Command{Command: "switch_session", Version: 2}
+
+	legacy := PluginInstance{ID: "plugin-1"}
+	require.True(t, legacy.Supports(switchSession))
+	require.False(t, legacy.Supports(newer))
+
+	registered := PluginInstance{ID: "plugin-2", ProtocolVersion: 1, Commands: []string{"open_picker", "close_picker"}}
+	require.False(t, registered.Supports(switchSession))
+	require.False(t, registered.Supports(newer))
+
+	registered.Commands = append(registered.Commands, "switch_session")
+	require.True(t, registered.Supports(switchSession))
+	require.False(t, registered.Supports(newer))
+}
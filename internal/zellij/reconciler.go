@@ -0,0 +1,88 @@
+package zellij
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Reconciler keeps sessions current when no plugin instance is reporting, by
+// periodically asking Zellij for its session list.
+type Reconciler struct {
+	service  *ZellijService
+	lister   SessionLister
+	interval time.Duration
+	now      func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewReconciler(service *ZellijService, lister SessionLister, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		service:  service,
+		lister:   lister,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+func (r *Reconciler) OnAppStart(ctx context.Context) error {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run()
+
+	return nil
+}
+
+func (r *Reconciler) OnAppEnd(ctx context.Context) error {
+	close(r.stop)
+	<-r.done
+
+	return nil
+}
+
+func (r *Reconciler) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			if _, err := r.ReconcileIfSilent(ctx); err != nil {
+				log.Printf("Failed to reconcile zellij sessions: %v", err)
+			}
+		}
+	}
+}
+
+// ReconcileIfSilent reconciles unless a plugin reported within the last
+// interval, and reports whether it did.
+func (r *Reconciler) ReconcileIfSilent(ctx context.Context) (bool, error) {
+	if r.now().Sub(r.service.LastPluginUpdate()) < r.interval {
+		return false, nil
+	}
+
+	return true, r.Reconcile(ctx)
+}
+
+// Reconcile lists Zellij's sessions and applies them.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	listed, err := r.lister.ListSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.service.ReconcileSessions(ctx, listed)
+}
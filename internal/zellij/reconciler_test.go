@@ -0,0 +1,128 @@
+package zellij
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/session"
+	"github.com/stretchr/testify/require"
+)
+
+// staticLister returns a fixed listing, standing in for the zellij CLI.
+type staticLister struct {
+	sessions []ListedSession
+	err      error
+}
+
+func (l *staticLister) ListSessions(ctx context.Context) ([]ListedSession, error) {
+	return l.sessions, l.err
+}
+
+func setupReconciler(t *testing.T, lister SessionLister) (*Reconciler, *ZellijService, *session.SessionStore) {
+	t.Helper()
+
+	service, _, sessionStore := setupZellijService(t)
+	return NewReconciler(service, lister, time.Minute), service, sessionStore
+}
+
+func TestReconciler_Reconcile(t *testing.T) {
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	lister := &staticLister{sessions: []ListedSession{
+		{Name: "session-1", Current: true},
+		{Name: "discovered", CreatedAt: created},
+		{Name: "session-2", Exited: true},
+	}}
+	reconciler, _, sessionStore := setupReconciler(t, lister)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+	sessionStore.Add(&session.Session{ID: "session-2", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	require.NoError(t, reconciler.Reconcile(ctx))
+
+	sess1, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateAttached, sess1.State)
+
+	// Resurrectable sessions aren't running
+	sess2, err := sessionStore.GetByID("session-2")
+	require.NoError(t, err)
+	require.Equal(t, session.StateExited, sess2.State)
+
+	discovered, err := sessionStore.GetByID("discovered")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRunningDetached, discovered.State)
+	require.Equal(t, created, discovered.LastUsedAt)
+}
+
+func TestReconciler_Reconcile_KeepsAttachedWithoutCurrentMarker(t *testing.T) {
+	// Run outside zellij, list-sessions marks no session current
+	lister := &staticLister{sessions: []ListedSession{
+		{Name: "session-1"},
+		{Name: "session-2"},
+	}}
+	reconciler, _, sessionStore := setupReconciler(t, lister)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: time.Now()})
+	sessionStore.Add(&session.Session{ID: "session-2", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	require.NoError(t, reconciler.Reconcile(ctx))
+
+	sess1, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateAttached, sess1.State)
+
+	sess2, err := sessionStore.GetByID("session-2")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRunningDetached, sess2.State)
+}
+
+func TestReconciler_ReconcileIfSilent(t *testing.T) {
+	lister := &staticLister{sessions: []ListedSession{{Name: "session-1"}}}
+	reconciler, service, sessionStore := setupReconciler(t, lister)
+	ctx := context.Background()
+
+	// No plugin has ever reported
+	ran, err := reconciler.ReconcileIfSilent(ctx)
+	require.NoError(t, err)
+	require.True(t, ran)
+	require.Len(t, sessionStore.List(), 1)
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{}))
+
+	ran, err = reconciler.ReconcileIfSilent(ctx)
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	// The plugin has gone quiet for longer than the interval
+	reconciler.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	ran, err = reconciler.ReconcileIfSilent(ctx)
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+
+func TestReconciler_ListFails(t *testing.T) {
+	lister := &staticLister{err: errors.New("zellij list-sessions failed")}
+	reconciler, _, sessionStore := setupReconciler(t, lister)
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	err := reconciler.Reconcile(context.Background())
+	require.Error(t, err)
+
+	// A failed listing must not look like every session died
+	sess, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRunningDetached, sess.State)
+}
+
+func TestReconciler_StartStop(t *testing.T) {
+	reconciler, _, _ := setupReconciler(t, &staticLister{})
+	ctx := context.Background()
+
+	require.NoError(t, reconciler.OnAppStart(ctx))
+	require.NoError(t, reconciler.OnAppEnd(ctx))
+}
@@ -0,0 +1,69 @@
+package zellij
+
+import (
+	"context"
+	"sync"
+)
+
+// SentCommand is a command a RecordingSender was asked to send. Instance is
+// empty for untargeted sends.
+type SentCommand struct {
+	Instance string
+	Command  Command
+}
+
+// RecordingSender is a CommandSender for tests. It records every command it
+// is asked to send and fails with the error set by FailWith.
+type RecordingSender struct {
+	mu   sync.Mutex
+	sent []SentCommand
+	err  error
+}
+
+func NewRecordingSender() *RecordingSender {
+	return &RecordingSender{}
+}
+
+func (s *RecordingSender) SendCommand(ctx context.Context, cmd Command) error {
+	return s.record(SentCommand{Command: cmd})
+}
+
+func (s *RecordingSender) SendCommandTo(ctx context.Context, instance PluginInstance, cmd Command) error {
+	return s.record(SentCommand{Instance: instance.ID, Command: cmd})
+}
+
+func (s *RecordingSender) record(sent SentCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent = append(s.sent, sent)
+	return s.err
+}
+
+// Commands returns every command sent so far, including failed attempts.
+func (s *RecordingSender) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commands := make([]Command, len(s.sent))
+	for i, sent := range s.sent {
+		commands[i] = sent.Command
+	}
+	return commands
+}
+
+// Sent returns every command sent so far with the instance it targeted.
+func (s *RecordingSender) Sent() []SentCommand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]SentCommand(nil), s.sent...)
+}
+
+// FailWith makes later sends fail with err; nil makes them succeed again.
+func (s *RecordingSender) FailWith(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
@@ -0,0 +1,89 @@
+package zellij
+
+import (
+	"sort"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/session"
+	"github.com/eleonorayaya/utena/internal/workspace"
+)
+
+// SessionDiff is what a reported session list changes about the stored
+// sessions. Sessions the report doesn't change are in neither list.
+type SessionDiff struct {
+	Create []session.Session
+	Update []session.Session
+}
+
+func (d SessionDiff) IsEmpty() bool {
+	return len(d.Create) == 0 && len(d.Update) == 0
+}
+
+// diffSessions works out the changes a report makes to the stored sessions
+// without touching any state. Only a session that becomes attached has its
+// recency bumped; the report says nothing about when the others were used,
+// and instances reporting in turn don't move it back and forth.
+func diffSessions(stored []session.Session, reported []SessionUpdate, now time.Time, inferWorkspaceID func(cwd string) string) SessionDiff {
+	var diff SessionDiff
+
+	updates := make(map[string]SessionUpdate, len(reported))
+	for _, update := range reported {
+		updates[update.Name] = update
+	}
+
+	for _, existing := range stored {
+		update, reportedNow := updates[existing.ID]
+		delete(updates, existing.ID)
+
+		changed := existing
+		switch {
+		case reportedNow:
+			if existing.WorkspaceID == workspace.UnassignedWorkspaceID && update.Cwd != "" {
+				changed.WorkspaceID = inferWorkspaceID(update.Cwd)
+			}
+			changed.State = runningState(update)
+			if changed.IsAttached() && !existing.IsAttached() {
+				changed.LastUsedAt = now
+			}
+		case existing.IsActive():
+			changed.State = session.StateExited
+		default:
+			// Requested and starting sessions may not have reached Zellij
+			// yet, and dead ones are already dead.
+		}
+
+		if changed.State != existing.State || changed.WorkspaceID != existing.WorkspaceID || !changed.LastUsedAt.Equal(existing.LastUsedAt) {
+			diff.Update = append(diff.Update, changed)
+		}
+	}
+
+	for name, update := range updates {
+		// A session found by listing hasn't necessarily been used since it
+		// was created, so don't rank it above ones that have.
+		lastUsedAt := update.CreatedAt
+		if lastUsedAt.IsZero() || update.attached() {
+			lastUsedAt = now
+		}
+
+		diff.Create = append(diff.Create, session.Session{
+			ID:          name,
+			WorkspaceID: inferWorkspaceID(update.Cwd),
+			State:       runningState(update),
+			LastUsedAt:  lastUsedAt,
+		})
+	}
+
+	// Map iteration order would otherwise leak into event order
+	sort.Slice(diff.Create, func(i, j int) bool {
+		return diff.Create[i].ID < diff.Create[j].ID
+	})
+
+	return diff
+}
+
+func runningState(update SessionUpdate) session.State {
+	if update.attached() {
+		return session.StateAttached
+	}
+	return session.StateRunningDetached
+}
@@ -0,0 +1,116 @@
+package zellij
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/session"
+	"github.com/eleonorayaya/utena/internal/workspace"
+	"github.com/stretchr/testify/require"
+)
+
+func inferTestWorkspace(cwd string) string {
+	if cwd == "" {
+		return workspace.UnassignedWorkspaceID
+	}
+	return "ws-1"
+}
+
+func TestDiffSessions_UnchangedReportIsEmpty(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	stored := []session.Session{
+		{ID: "attached", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: earlier},
+		{ID: "detached", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier},
+		{ID: "exited", WorkspaceID: "ws-1", State: session.StateExited, LastUsedAt: earlier},
+	}
+	reported := []SessionUpdate{
+		{Name: "attached", IsCurrentSession: true, ConnectedClients: 1},
+		{Name: "detached"},
+	}
+
+	diff := diffSessions(stored, reported, time.Now(), inferTestWorkspace)
+	require.True(t, diff.IsEmpty())
+}
+
+func TestDiffSessions_OtherInstanceReportIsEmpty(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	stored := []session.Session{
+		{ID: "utena-main", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: earlier},
+		{ID: "other", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier},
+	}
+
+	// The instance in the detached session marks it as current
+	_, fromOther := twoInstanceReports()
+	diff := diffSessions(stored, fromOther.Sessions, time.Now(), inferTestWorkspace)
+	require.True(t, diff.IsEmpty())
+}
+
+func TestDiffSessions_OnlyNewlyAttachedSessionIsBumped(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	now := time.Now()
+	stored := []session.Session{
+		{ID: "was-attached", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: earlier},
+		{ID: "now-attached", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier},
+		{ID: "still-detached", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier},
+	}
+	reported := []SessionUpdate{
+		{Name: "was-attached"},
+		{Name: "now-attached", IsCurrentSession: true, ConnectedClients: 1},
+		{Name: "still-detached"},
+	}
+
+	diff := diffSessions(stored, reported, now, inferTestWorkspace)
+	require.Empty(t, diff.Create)
+	require.Len(t, diff.Update, 2)
+
+	require.Equal(t, "was-attached", diff.Update[0].ID)
+	require.Equal(t, session.StateRunningDetached, diff.Update[0].State)
+	require.Equal(t, earlier, diff.Update[0].LastUsedAt)
+
+	require.Equal(t, "now-attached", diff.Update[1].ID)
+	require.Equal(t, session.StateAttached, diff.Update[1].State)
+	require.Equal(t, now, diff.Update[1].LastUsedAt)
+}
+
+func TestDiffSessions_CreatesAndExits(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	created := time.Now().Add(-2 * time.Hour)
+	now := time.Now()
+	stored := []session.Session{
+		{ID: "gone", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier},
+		{ID: "requested", WorkspaceID: "ws-1", State: session.StateRequested, LastUsedAt: earlier},
+	}
+	reported := []SessionUpdate{
+		{Name: "listed", CreatedAt: created},
+		{Name: "current", IsCurrentSession: true, ConnectedClients: 1, Cwd: "/tmp/utena"},
+	}
+
+	diff := diffSessions(stored, reported, now, inferTestWorkspace)
+
+	require.Len(t, diff.Update, 1)
+	require.Equal(t, "gone", diff.Update[0].ID)
+	require.Equal(t, session.StateExited, diff.Update[0].State)
+
+	require.Equal(t, []session.Session{
+		{ID: "current", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: now},
+		{ID: "listed", WorkspaceID: workspace.UnassignedWorkspaceID, State: session.StateRunningDetached, LastUsedAt: created},
+	}, diff.Create)
+}
+
+func TestDiffSessions_AssignsWorkspaceOnce(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+	stored := []session.Session{
+		{ID: "unassigned", WorkspaceID: workspace.UnassignedWorkspaceID, State: session.StateRunningDetached, LastUsedAt: earlier},
+		{ID: "assigned", WorkspaceID: "ws-2", State: session.StateRunningDetached, LastUsedAt: earlier},
+	}
+	reported := []SessionUpdate{
+		{Name: "unassigned", Cwd: "/tmp/utena"},
+		{Name: "assigned", Cwd: "/tmp/utena"},
+	}
+
+	diff := diffSessions(stored, reported, time.Now(), inferTestWorkspace)
+	require.Len(t, diff.Update, 1)
+	require.Equal(t, "unassigned", diff.Update[0].ID)
+	require.Equal(t, "ws-1", diff.Update[0].WorkspaceID)
+	require.Equal(t, earlier, diff.Update[0].LastUsedAt)
+}
@@ -0,0 +1,153 @@
+package zellij
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultListSessionsTimeout = 5 * time.Second
+
+var ErrUnparseableSessionList = errors.New("unrecognized zellij list-sessions output")
+
+// ListedSession is one line of `zellij list-sessions --no-formatting`.
+type ListedSession struct {
+	Name string
+	// Current marks the session the listing process runs in.
+	Current bool
+	// Exited sessions are no longer running but can be resurrected.
+	Exited    bool
+	CreatedAt time.Time
+}
+
+// SessionLister reports the sessions Zellij itself knows about.
+type SessionLister interface {
+	ListSessions(ctx context.Context) ([]ListedSession, error)
+}
+
+// CLISessionLister runs `zellij list-sessions --no-formatting`.
+type CLISessionLister struct {
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewCLISessionLister(timeout time.Duration) *CLISessionLister {
+	if timeout <= 0 {
+		timeout = defaultListSessionsTimeout
+	}
+
+	return &CLISessionLister{
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+func (l *CLISessionLister) ListSessions(ctx context.Context) ([]ListedSession, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "zellij", "list-sessions", "--no-formatting").CombinedOutput()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("zellij list-sessions failed: %w", ctxErr)
+	}
+	// zellij exits non-zero when there is nothing to list
+	if err != nil && !noSessions(output) {
+		return nil, fmt.Errorf("zellij list-sessions failed: %w, output: %s", err, output)
+	}
+
+	return ParseSessionList(output, l.now())
+}
+
+var listedSessionPattern = regexp.MustCompile(`^(.+) \[Created (.+) ago\](?: \((current|EXITED - attach to resurrect)\))?$`)
+
+// ParseSessionList parses `zellij list-sessions --no-formatting` output.
+// Creation times are relative to now.
+func ParseSessionList(output []byte, now time.Time) ([]ListedSession, error) {
+	sessions := []ListedSession{}
+	if noSessions(output) {
+		return sessions, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		match := listedSessionPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("%w: line %d: %q", ErrUnparseableSessionList, lineNo, line)
+		}
+
+		age, err := parseAge(match[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrUnparseableSessionList, lineNo, err)
+		}
+
+		sessions = append(sessions, ListedSession{
+			Name:      match[1],
+			Current:   match[3] == "current",
+			Exited:    strings.HasPrefix(match[3], "EXITED"),
+			CreatedAt: now.Add(-age),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func noSessions(output []byte) bool {
+	return bytes.Contains(output, []byte("No active zellij sessions found"))
+}
+
+var ageUnits = map[string]time.Duration{
+	"year":  time.Duration(365.25 * float64(24*time.Hour)),
+	"month": time.Duration(30.44 * float64(24*time.Hour)),
+	"day":   24 * time.Hour,
+	"h":     time.Hour,
+	"m":     time.Minute,
+	"s":     time.Second,
+	"ms":    time.Millisecond,
+	"us":    time.Microsecond,
+	"ns":    time.Nanosecond,
+}
+
+var ageComponentPattern = regexp.MustCompile(`^(\d+)([a-z]+)$`)
+
+// parseAge parses the humantime durations zellij prints, e.g. "1day 2h 3m".
+func parseAge(s string) (time.Duration, error) {
+	var age time.Duration
+	for _, component := range strings.Fields(s) {
+		match := ageComponentPattern.FindStringSubmatch(component)
+		if match == nil {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+
+		unit, ok := ageUnits[match[2]]
+		if !ok {
+			unit, ok = ageUnits[strings.TrimSuffix(match[2], "s")]
+		}
+		if !ok {
+			return 0, fmt.Errorf("invalid age unit in %q", s)
+		}
+
+		n, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q: %w", s, err)
+		}
+		age += time.Duration(n) * unit
+	}
+
+	return age, nil
+}
@@ -0,0 +1,81 @@
+package zellij
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var listedAt = time.Date(2026, 1, 27, 12, 0, 0, 0, time.UTC)
+
+func parseFixture(t *testing.T, name string) ([]ListedSession, error) {
+	t.Helper()
+
+	output, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	return ParseSessionList(output, listedAt)
+}
+
+func TestParseSessionList(t *testing.T) {
+	sessions, err := parseFixture(t, "list_sessions.txt")
+	require.NoError(t, err)
+
+	require.Equal(t, []ListedSession{
+		{Name: "utena-main", Current: true, CreatedAt: listedAt.Add(-(2*time.Hour + 3*time.Minute + 10*time.Second))},
+		{Name: "example-project", CreatedAt: listedAt.Add(-(5*time.Minute + 2*time.Second))},
+		{Name: "old-work", Exited: true, CreatedAt: listedAt.Add(-(3*24*time.Hour + 4*time.Hour))},
+	}, sessions)
+}
+
+func TestParseSessionList_NoSessions(t *testing.T) {
+	sessions, err := parseFixture(t, "list_sessions_empty.txt")
+	require.NoError(t, err)
+	require.NotNil(t, sessions)
+	require.Empty(t, sessions)
+}
+
+func TestParseSessionList_Ages(t *testing.T) {
+	sessions, err := parseFixture(t, "list_sessions_ages.txt")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	// Names may contain spaces; humantime units may be plural
+	require.Equal(t, "my session", sessions[0].Name)
+	age := ageUnits["year"] + 2*ageUnits["month"] + 24*time.Hour + 10*time.Second
+	require.Equal(t, listedAt.Add(-age), sessions[0].CreatedAt)
+
+	require.Equal(t, "fresh", sessions[1].Name)
+	require.Equal(t, listedAt, sessions[1].CreatedAt)
+}
+
+func TestParseSessionList_Invalid(t *testing.T) {
+	_, err := parseFixture(t, "list_sessions_invalid.txt")
+	require.ErrorIs(t, err, ErrUnparseableSessionList)
+	require.Contains(t, err.Error(), "line 2")
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"10s":       10 * time.Second,
+		"1h 30m":    90 * time.Minute,
+		"2days 1h":  49 * time.Hour,
+		"1day":      24 * time.Hour,
+		"250ms":     250 * time.Millisecond,
+		"1m 1s 1ms": time.Minute + time.Second + time.Millisecond,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := parseAge(input)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := parseAge("3 fortnights")
+	require.Error(t, err)
+}
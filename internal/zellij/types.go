@@ -1,16 +1,117 @@
 package zellij
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/eleonorayaya/utena/internal/session"
+)
 
 type SessionUpdate struct {
 	Name             string `json:"name"`
 	IsCurrentSession bool   `json:"is_current_session"`
+	Cwd              string `json:"cwd,omitempty"`
+	// Tabs and Panes are omitted by plugins that predate snapshots, in
+	// which case the previous snapshot is kept.
+	Tabs             []TabInfo  `json:"tabs,omitempty"`
+	Panes            []PaneInfo `json:"panes,omitempty"`
+	ConnectedClients int        `json:"connected_clients"`
+	// CreatedAt is only known when reconciling from `zellij list-sessions`.
+	CreatedAt time.Time `json:"-"`
+}
+
+// TabInfo mirrors the fields of Zellij's TabInfo the daemon keeps.
+type TabInfo struct {
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	Active   bool   `json:"active"`
+}
+
+// PaneInfo mirrors the fields of Zellij's PaneInfo the daemon keeps.
+type PaneInfo struct {
+	ID              uint32 `json:"id"`
+	TabPosition     int    `json:"tab_position"`
+	Title           string `json:"title"`
+	IsPlugin        bool   `json:"is_plugin"`
+	IsFocused       bool   `json:"is_focused"`
+	IsFloating      bool   `json:"is_floating"`
+	TerminalCommand string `json:"terminal_command,omitempty"`
+	PluginURL       string `json:"plugin_url,omitempty"`
+	Exited          bool   `json:"exited"`
+	ExitStatus      *int   `json:"exit_status,omitempty"`
+}
+
+// attached reports whether a client is connected to the session. Every
+// plugin instance reports its own host session as current, so that marker
+// says nothing about which sessions someone is looking at.
+func (u SessionUpdate) attached() bool {
+	return u.ConnectedClients > 0
+}
+
+// hasSnapshot reports whether the update carries tabs and panes.
+func (u SessionUpdate) hasSnapshot() bool {
+	return u.Tabs != nil || u.Panes != nil
+}
+
+func (u SessionUpdate) snapshot(reportedAt time.Time) *session.Snapshot {
+	snapshot := &session.Snapshot{
+		Tabs:             make([]session.Tab, 0, len(u.Tabs)),
+		Panes:            make([]session.Pane, 0, len(u.Panes)),
+		ConnectedClients: u.ConnectedClients,
+		Cwd:              u.Cwd,
+		ReportedAt:       reportedAt,
+	}
+
+	for _, tab := range u.Tabs {
+		snapshot.Tabs = append(snapshot.Tabs, session.Tab{
+			Position: tab.Position,
+			Name:     tab.Name,
+			Active:   tab.Active,
+		})
+	}
+
+	for _, pane := range u.Panes {
+		command := pane.TerminalCommand
+		if pane.IsPlugin {
+			command = pane.PluginURL
+		}
+
+		snapshot.Panes = append(snapshot.Panes, session.Pane{
+			ID:          pane.ID,
+			TabPosition: pane.TabPosition,
+			Title:       pane.Title,
+			IsPlugin:    pane.IsPlugin,
+			IsFocused:   pane.IsFocused,
+			IsFloating:  pane.IsFloating,
+			Command:     command,
+			Exited:      pane.Exited,
+			ExitStatus:  pane.ExitStatus,
+		})
+	}
+
+	return snapshot
 }
 
 type UpdateSessionsRequest struct {
+	// ID identifies the reporting plugin instance. Older plugins leave it
+	// empty and are not tracked.
+	ID       string          `json:"id"`
+	Version  string          `json:"version,omitempty"`
 	Sessions []SessionUpdate `json:"sessions"`
 }
 
+// hostSession is the session the reporting instance runs in, which Zellij
+// reports as current.
+func (u *UpdateSessionsRequest) hostSession() string {
+	for _, s := range u.Sessions {
+		if s.IsCurrentSession {
+			return s.Name
+		}
+	}
+	return ""
+}
+
 func (u *UpdateSessionsRequest) Bind(r *http.Request) error {
 
 	if u.Sessions == nil {
@@ -18,3 +119,131 @@ func (u *UpdateSessionsRequest) Bind(r *http.Request) error {
 	}
 	return nil
 }
+
+// CommandResultRequest is the outcome the plugin reports for a command.
+type CommandResultRequest struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (c *CommandResultRequest) Bind(r *http.Request) error {
+
+	if !c.Success && c.Error == "" {
+		return errors.New("failed commands must include an error")
+	}
+	return nil
+}
+
+type CommandStatusResponse struct {
+	CommandStatus
+}
+
+func NewCommandStatusResponse(status CommandStatus) *CommandStatusResponse {
+	return &CommandStatusResponse{CommandStatus: status}
+}
+
+func (csr *CommandStatusResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+type CommandListResponse struct {
+	Commands []Command `json:"commands"`
+}
+
+func NewCommandListResponse(commands []Command) *CommandListResponse {
+	return &CommandListResponse{Commands: commands}
+}
+
+func (clr *CommandListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+type InstanceListResponse struct {
+	Instances []PluginInstance `json:"instances"`
+}
+
+func NewInstanceListResponse(instances []PluginInstance) *InstanceListResponse {
+	return &InstanceListResponse{Instances: instances}
+}
+
+func (ilr *InstanceListResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+// RegisterRequest is the handshake a plugin instance sends when it loads,
+// declaring the protocol it speaks and the commands it can execute.
+type RegisterRequest struct {
+	ID              string   `json:"id"`
+	Version         string   `json:"version,omitempty"`
+	HostSession     string   `json:"host_session,omitempty"`
+	ProtocolVersion int      `json:"protocol_version"`
+	Commands        []string `json:"commands"`
+}
+
+func (rr *RegisterRequest) Bind(r *http.Request) error {
+
+	if rr.ID == "" {
+		return errors.New("id is required")
+	}
+	if rr.ProtocolVersion <= 0 {
+		return errors.New("protocol_version is required")
+	}
+	if rr.Commands == nil {
+		rr.Commands = []string{}
+	}
+	return nil
+}
+
+// RegisterResponse tells the plugin which protocol version to speak.
+type RegisterResponse struct {
+	ProtocolVersion    int            `json:"protocol_version"`
+	MinProtocolVersion int            `json:"min_protocol_version"`
+	MaxProtocolVersion int            `json:"max_protocol_version"`
+	Instance           PluginInstance `json:"instance"`
+}
+
+func NewRegisterResponse(instance PluginInstance) *RegisterResponse {
+	return &RegisterResponse{
+		ProtocolVersion:    instance.ProtocolVersion,
+		MinProtocolVersion: MinProtocolVersion,
+		MaxProtocolVersion: ProtocolVersion,
+		Instance:           instance,
+	}
+}
+
+func (rr *RegisterResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
+
+// HeartbeatRequest is sent by every plugin instance periodically.
+type HeartbeatRequest struct {
+	ID          string `json:"id"`
+	Version     string `json:"version,omitempty"`
+	HostSession string `json:"host_session,omitempty"`
+}
+
+func (h *HeartbeatRequest) Bind(r *http.Request) error {
+
+	if h.ID == "" {
+		return errors.New("id is required")
+	}
+	return nil
+}
+
+type StatusResponse struct {
+	ConnectivityStatus
+	Instances int `json:"instances"`
+}
+
+func NewStatusResponse(status ConnectivityStatus, instances int) *StatusResponse {
+	return &StatusResponse{ConnectivityStatus: status, Instances: instances}
+}
+
+func (sr *StatusResponse) Render(w http.ResponseWriter, r *http.Request) error {
+
+	return nil
+}
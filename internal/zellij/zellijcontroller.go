@@ -1,12 +1,25 @@
 package zellij
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/eleonorayaya/utena/internal/common"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 )
 
+const (
+	// maxCommandWait bounds ?wait= on GET /zellij/commands/{id}.
+	maxCommandWait = 30 * time.Second
+
+	defaultPollTimeout = 25 * time.Second
+	maxPollTimeout     = 60 * time.Second
+)
+
 type ZellijController struct {
 	service *ZellijService
 }
@@ -33,3 +46,149 @@ func (c *ZellijController) UpdateSessions(w http.ResponseWriter, r *http.Request
 
 	render.JSON(w, r, map[string]string{"status": "ok"})
 }
+
+// Register answers a plugin's handshake. An incompatible protocol version is
+// a 409, telling the plugin to stop talking to this daemon.
+func (c *ZellijController) Register(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req := &RegisterRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Render(w, r, common.ErrInvalidRequest(err))
+		return
+	}
+
+	instance, err := c.service.Register(ctx, req)
+	if err != nil {
+		if errors.Is(err, ErrIncompatibleProtocol) {
+			render.Render(w, r, common.ErrConflict(err))
+			return
+		}
+		render.Render(w, r, common.ErrUnknown(err))
+		return
+	}
+
+	render.Render(w, r, NewRegisterResponse(instance))
+}
+
+func (c *ZellijController) Heartbeat(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req := &HeartbeatRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Render(w, r, common.ErrInvalidRequest(err))
+		return
+	}
+
+	status := c.service.Heartbeat(ctx, req)
+	render.Render(w, r, NewStatusResponse(status, len(c.service.ListInstances(ctx))))
+}
+
+func (c *ZellijController) GetStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	status := c.service.Status(ctx)
+	render.Render(w, r, NewStatusResponse(status, len(c.service.ListInstances(ctx))))
+}
+
+func (c *ZellijController) ListInstances(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	instances := c.service.ListInstances(ctx)
+	render.Render(w, r, NewInstanceListResponse(instances))
+}
+
+// PollCommands long-polls for commands for ?instance=. It answers as soon as
+// commands are due, or with an empty list after ?timeout= (default 25s).
+func (c *ZellijController) PollCommands(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	instance := r.URL.Query().Get("instance")
+	if instance == "" {
+		render.Render(w, r, common.ErrInvalidRequest(errors.New("instance is required")))
+		return
+	}
+
+	timeout := defaultPollTimeout
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			render.Render(w, r, common.ErrInvalidRequest(fmt.Errorf("invalid timeout %q", value)))
+			return
+		}
+		timeout = min(parsed, maxPollTimeout)
+	}
+
+	pollCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	commands, err := c.service.PollCommands(pollCtx, instance)
+	if err != nil {
+		if errors.Is(err, ErrPollingDisabled) {
+			render.Render(w, r, common.ErrConflict(err))
+			return
+		}
+		render.Render(w, r, common.ErrUnknown(err))
+		return
+	}
+
+	render.Render(w, r, NewCommandListResponse(commands))
+}
+
+// GetCommand returns a command's status. With ?wait=<duration> (e.g. 5s) it
+// holds the request until the command is done or the wait elapses.
+func (c *ZellijController) GetCommand(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	var (
+		status CommandStatus
+		err    error
+	)
+	if wait := r.URL.Query().Get("wait"); wait != "" {
+		timeout, parseErr := time.ParseDuration(wait)
+		if parseErr != nil || timeout < 0 {
+			render.Render(w, r, common.ErrInvalidRequest(fmt.Errorf("invalid wait %q", wait)))
+			return
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, min(timeout, maxCommandWait))
+		defer cancel()
+		status, err = c.service.WaitForCommand(waitCtx, id)
+	} else {
+		status, err = c.service.GetCommand(ctx, id)
+	}
+
+	if err != nil {
+		render.Render(w, r, common.ErrNotFound())
+		return
+	}
+
+	render.Render(w, r, NewCommandStatusResponse(status))
+}
+
+func (c *ZellijController) ReportCommandResult(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
+
+	req := &CommandResultRequest{}
+	if err := render.Bind(r, req); err != nil {
+		render.Render(w, r, common.ErrInvalidRequest(err))
+		return
+	}
+
+	status, err := c.service.CompleteCommand(ctx, id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCommandNotFound):
+			render.Render(w, r, common.ErrNotFound())
+		case errors.Is(err, ErrCommandCompleted):
+			render.Render(w, r, common.ErrConflict(err))
+		default:
+			render.Render(w, r, common.ErrUnknown(err))
+		}
+		return
+	}
+
+	render.Render(w, r, NewCommandStatusResponse(status))
+}
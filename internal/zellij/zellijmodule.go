@@ -2,7 +2,9 @@ package zellij
 
 import (
 	"context"
+	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/session"
 	"github.com/go-chi/chi/v5"
@@ -12,17 +14,43 @@ type ZellijModule struct {
 	Service    *ZellijService
 	Controller *ZellijController
 	Router     *ZellijRouter
+	// Reconciler is nil when reconcile_interval_ms is 0.
+	Reconciler *Reconciler
 }
 
-func NewZellijModule(sessionModule *session.SessionModule, bus eventbus.EventBus) *ZellijModule {
-	service := NewZellijService(sessionModule.Service, bus)
+// NewZellijModule builds the module from cfg. Options are applied after the
+// configured ones, e.g. WithCommandSender to replace the transport in tests.
+func NewZellijModule(cfg *config.Config, sessionModule *session.SessionModule, bus eventbus.EventBus, deadLetters *eventbus.DeadLetterStore, opts ...ZellijOption) *ZellijModule {
+	configured := []ZellijOption{
+		WithDeadLetters(deadLetters),
+		WithCommandTimeout(time.Duration(cfg.CommandTimeoutMs) * time.Millisecond),
+		WithCommandSender(newCommandSender(cfg)),
+		WithUpdateCoalescing(time.Duration(cfg.SessionUpdateCoalesceMs) * time.Millisecond),
+		WithHeartbeatInterval(time.Duration(cfg.HeartbeatIntervalMs) * time.Millisecond),
+	}
+
+	// A sync bus runs handlers on the publishing request's goroutine, where
+	// retry backoff would hold up the response. Validate makes sure no
+	// retries were configured for it.
+	if cfg.EventDelivery != config.EventDeliverySync {
+		configured = append(configured, WithRetry(newRetryPolicy(cfg)))
+	}
+
+	service := NewZellijService(sessionModule.Service, bus, append(configured, opts...)...)
 	controller := NewZellijController(service)
 	router := NewZellijRouter(controller)
 
+	var reconciler *Reconciler
+	if cfg.ReconcileIntervalMs > 0 {
+		interval := time.Duration(cfg.ReconcileIntervalMs) * time.Millisecond
+		reconciler = NewReconciler(service, NewCLISessionLister(defaultListSessionsTimeout), interval)
+	}
+
 	return &ZellijModule{
 		Service:    service,
 		Controller: controller,
 		Router:     router,
+		Reconciler: reconciler,
 	}
 }
 
@@ -32,11 +60,23 @@ func (m *ZellijModule) OnAppStart(ctx context.Context) error {
 		return err
 	}
 
+	if m.Reconciler != nil {
+		if err := m.Reconciler.OnAppStart(ctx); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (m *ZellijModule) OnAppEnd(ctx context.Context) error {
 
+	if m.Reconciler != nil {
+		if err := m.Reconciler.OnAppEnd(ctx); err != nil {
+			return err
+		}
+	}
+
 	if err := m.Service.OnAppEnd(ctx); err != nil {
 		return err
 	}
@@ -47,3 +87,19 @@ func (m *ZellijModule) OnAppEnd(ctx context.Context) error {
 func (m *ZellijModule) Routes() chi.Router {
 	return m.Router.Routes()
 }
+
+func newRetryPolicy(cfg *config.Config) eventbus.RetryPolicy {
+	policy := eventbus.DefaultRetryPolicy()
+	policy.Attempts = cfg.EventRetryAttempts
+	policy.InitialBackoff = time.Duration(cfg.EventRetryBackoffMs) * time.Millisecond
+	policy.MaxBackoff = time.Duration(cfg.EventRetryMaxBackoffMs) * time.Millisecond
+	policy.Jitter = cfg.EventRetryJitter
+	return policy
+}
+
+func newCommandSender(cfg *config.Config) CommandSender {
+	if cfg.CommandTransport == config.CommandTransportPoll {
+		return NewPollSender(time.Duration(cfg.CommandAckTimeoutMs) * time.Millisecond)
+	}
+	return NewPipeSender(time.Duration(cfg.PipeTimeoutMs) * time.Millisecond)
+}
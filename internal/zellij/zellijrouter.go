@@ -18,6 +18,13 @@ func (zr *ZellijRouter) Routes() chi.Router {
 	r := chi.NewRouter()
 
 	r.Put("/sessions", zr.controller.UpdateSessions)
+	r.Get("/instances", zr.controller.ListInstances)
+	r.Post("/register", zr.controller.Register)
+	r.Post("/heartbeat", zr.controller.Heartbeat)
+	r.Get("/status", zr.controller.GetStatus)
+	r.Get("/commands", zr.controller.PollCommands)
+	r.Get("/commands/{id}", zr.controller.GetCommand)
+	r.Post("/commands/{id}/result", zr.controller.ReportCommandResult)
 
 	return r
 }
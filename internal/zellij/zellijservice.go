@@ -2,75 +2,358 @@ package zellij
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/common"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/session"
 )
 
+var ErrPollingDisabled = errors.New("command polling is disabled, the command transport is pipe")
+
+type ZellijOption func(*ZellijService)
+
+// WithRetry retries failed command handlers under policy.
+func WithRetry(policy eventbus.RetryPolicy) ZellijOption {
+	return func(z *ZellijService) {
+		z.retryPolicy = &policy
+	}
+}
+
+// WithDeadLetters keeps command events whose handler still fails in
+// deadLetters, so they can be re-driven.
+func WithDeadLetters(deadLetters *eventbus.DeadLetterStore) ZellijOption {
+	return func(z *ZellijService) {
+		z.deadLetters = deadLetters
+	}
+}
+
+// WithCommandTimeout sets how long the plugin has to report a command's
+// outcome before it is marked timed out.
+func WithCommandTimeout(timeout time.Duration) ZellijOption {
+	return func(z *ZellijService) {
+		z.commands = NewCommandTracker(timeout)
+	}
+}
+
+// WithCommandSender replaces the default `zellij pipe` sender. Passing a
+// PollSender enables GET /zellij/commands.
+func WithCommandSender(sender CommandSender) ZellijOption {
+	return func(z *ZellijService) {
+		z.sender = sender
+		z.poller, _ = sender.(*PollSender)
+	}
+}
+
+// WithUpdateCoalescing drops plugin updates identical to the last one applied
+// within window. Every session's plugin instance reports each change, so
+// updates arrive in bursts.
+func WithUpdateCoalescing(window time.Duration) ZellijOption {
+	return func(z *ZellijService) {
+		z.coalesceWindow = window
+	}
+}
+
+// WithHeartbeatInterval sets how often plugins are expected to check in.
+// Connectivity degrades after two missed intervals.
+func WithHeartbeatInterval(interval time.Duration) ZellijOption {
+	return func(z *ZellijService) {
+		z.liveness = NewLivenessTracker(z.eventBus, interval)
+	}
+}
+
 type ZellijService struct {
 	sessionService *session.SessionService
 	eventBus       eventbus.EventBus
-	pipeSender     *PipeSender
+	sender         CommandSender
+	poller         *PollSender
+	commands       *CommandTracker
+	instances      *InstanceRegistry
+	liveness       *LivenessTracker
+	subscriptions  []eventbus.Subscription
+
+	// updateMu serializes plugin updates and reconciliation, which both
+	// read and then rewrite the session list.
+	updateMu         sync.Mutex
+	lastPluginUpdate time.Time
+	coalesceWindow   time.Duration
+	lastApplied      [sha256.Size]byte
+	lastAppliedAt    time.Time
+
+	// lifetime is cancelled in OnAppEnd so in-flight sends are abandoned on
+	// shutdown even though handler contexts outlive their request.
+	lifetime context.Context
+	shutdown context.CancelFunc
+
+	retryPolicy *eventbus.RetryPolicy
+	deadLetters *eventbus.DeadLetterStore
 }
 
-func NewZellijService(sessionService *session.SessionService, bus eventbus.EventBus) *ZellijService {
-	return &ZellijService{
+func NewZellijService(sessionService *session.SessionService, bus eventbus.EventBus, opts ...ZellijOption) *ZellijService {
+	lifetime, shutdown := context.WithCancel(context.Background())
+
+	z := &ZellijService{
 		sessionService: sessionService,
 		eventBus:       bus,
-		pipeSender:     NewPipeSender(),
+		sender:         NewPipeSender(defaultPipeTimeout),
+		commands:       NewCommandTracker(defaultCommandTimeout),
+		instances:      NewInstanceRegistry(),
+		liveness:       NewLivenessTracker(bus, defaultHeartbeatInterval),
+		lifetime:       lifetime,
+		shutdown:       shutdown,
+	}
+
+	for _, opt := range opts {
+		opt(z)
 	}
+
+	return z
 }
 
 func (z *ZellijService) OnAppStart(ctx context.Context) error {
-	z.eventBus.Subscribe(eventbus.SessionCreateRequested, z.handleSessionCreateRequested)
-	return nil
+	z.subscriptions = []eventbus.Subscription{
+		eventbus.Subscribe(z.eventBus, z.handleSessionCreateRequested, z.middleware("zellij.create_session")...),
+		eventbus.Subscribe(z.eventBus, z.handleSessionActivateRequested, z.middleware("zellij.switch_session")...),
+	}
+	return z.liveness.OnAppStart(ctx)
 }
 
 func (z *ZellijService) OnAppEnd(ctx context.Context) error {
+	for _, sub := range z.subscriptions {
+		sub.Unsubscribe()
+	}
+	z.subscriptions = nil
+
+	if err := z.liveness.OnAppEnd(ctx); err != nil {
+		return err
+	}
+
+	z.shutdown()
+	z.commands.Close()
 	return nil
 }
 
+// middleware wraps a command handler so a briefly unavailable zellij pipe
+// doesn't lose the command. The command only fails once the handler gives up,
+// outside the retries but inside the dead-letter store so a failed redrive
+// fails it again.
+func (z *ZellijService) middleware(subscriber string) []eventbus.Middleware {
+	var mws []eventbus.Middleware
+	if z.deadLetters != nil {
+		mws = append(mws, z.deadLetters.Middleware(subscriber))
+	}
+	mws = append(mws, z.failCommand)
+	if z.retryPolicy != nil {
+		mws = append(mws, eventbus.Retry(*z.retryPolicy))
+	}
+	return mws
+}
+
+// failCommand marks the command carried by a command event as failed when
+// the wrapped handler returns an error.
+func (z *ZellijService) failCommand(next eventbus.Handler) eventbus.Handler {
+	return func(ctx context.Context, event eventbus.Event) error {
+		err := next(ctx, event)
+		if err != nil {
+			if id := commandID(event); id != "" {
+				z.commands.Complete(id, err)
+			}
+		}
+		return err
+	}
+}
+
+func commandID(event eventbus.Event) string {
+	switch data := event.Data.(type) {
+	case eventbus.SessionCreateRequestedEvent:
+		return data.CommandID
+	case eventbus.SessionActivateRequestedEvent:
+		return data.CommandID
+	default:
+		return ""
+	}
+}
+
+// ProcessSessionUpdate applies the session list a plugin instance reported.
+// An instance repeating its previous report adds nothing and is skipped.
 func (z *ZellijService) ProcessSessionUpdate(ctx context.Context, req *UpdateSessionsRequest) error {
-	activeSessions := make(map[string]SessionUpdate)
-	for _, sessionUpdate := range req.Sessions {
-		activeSessions[sessionUpdate.Name] = sessionUpdate
+	z.updateMu.Lock()
+	defer z.updateMu.Unlock()
+
+	now := time.Now()
+	z.lastPluginUpdate = now
+	z.liveness.Seen(ctx)
+
+	fingerprint, err := fingerprintUpdate(req)
+	if err != nil {
+		return err
+	}
+	if req.ID != "" && !z.instances.Report(req.ID, req.hostSession(), req.Version, fingerprint) {
+		return nil
+	}
+	if z.coalesceWindow > 0 && fingerprint == z.lastApplied && now.Sub(z.lastAppliedAt) < z.coalesceWindow {
+		return nil
+	}
+
+	if err := z.applySessionUpdate(ctx, req); err != nil {
+		z.lastApplied = [sha256.Size]byte{}
+		z.instances.Invalidate()
+		return err
 	}
 
-	allSessions, err := z.sessionService.ListSessions(ctx)
+	z.lastApplied, z.lastAppliedAt = fingerprint, now
+	return nil
+}
+
+// Register completes the handshake with a plugin instance, agreeing on the
+// protocol version to speak. A plugin older than MinProtocolVersion is
+// rejected with ErrIncompatibleProtocol.
+func (z *ZellijService) Register(ctx context.Context, req *RegisterRequest) (PluginInstance, error) {
+	version, err := negotiateProtocol(req.ProtocolVersion)
+	if err != nil {
+		return PluginInstance{}, err
+	}
+
+	instance := z.instances.Register(req.ID, req.HostSession, req.Version, version, req.Commands)
+	z.liveness.Seen(ctx)
+	return instance, nil
+}
+
+// Heartbeat records that a plugin instance is alive.
+func (z *ZellijService) Heartbeat(ctx context.Context, req *HeartbeatRequest) ConnectivityStatus {
+	// A live plugin reports changes as they happen, so there is nothing to
+	// reconcile while it keeps checking in
+	z.updateMu.Lock()
+	z.lastPluginUpdate = time.Now()
+	z.updateMu.Unlock()
+
+	z.instances.Heartbeat(req.ID, req.HostSession, req.Version)
+	z.liveness.Seen(ctx)
+	return z.liveness.Status()
+}
+
+// Status reports whether plugins are checking in, so clients can warn that
+// session state may be stale.
+func (z *ZellijService) Status(ctx context.Context) ConnectivityStatus {
+	z.liveness.Check(ctx)
+	return z.liveness.Status()
+}
+
+// ListInstances returns the plugin instances that have reported, most
+// recently seen first.
+func (z *ZellijService) ListInstances(ctx context.Context) []PluginInstance {
+	return z.instances.List()
+}
+
+func fingerprintUpdate(req *UpdateSessionsRequest) ([sha256.Size]byte, error) {
+	data, err := json.Marshal(req.Sessions)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// LastPluginUpdate returns when a plugin last reported sessions or sent a
+// heartbeat, or the zero time if none has.
+func (z *ZellijService) LastPluginUpdate() time.Time {
+	z.updateMu.Lock()
+	defer z.updateMu.Unlock()
+
+	return z.lastPluginUpdate
+}
+
+// ReconcileSessions applies a `zellij list-sessions` listing as if a plugin
+// had reported it. Exited sessions count as gone.
+func (z *ZellijService) ReconcileSessions(ctx context.Context, listed []ListedSession) error {
+	z.updateMu.Lock()
+	defer z.updateMu.Unlock()
+
+	// The listing doesn't count clients and only marks a session current
+	// when the daemon runs inside it, so without a marker it says nothing
+	// about which one is attached
+	hasCurrent := false
+	for _, l := range listed {
+		hasCurrent = hasCurrent || l.Current
+	}
+	attached := make(map[string]bool)
+	if !hasCurrent {
+		stored, err := z.sessionService.ListSessions(ctx)
+		if err != nil {
+			return err
+		}
+		for _, sess := range stored {
+			attached[sess.ID] = sess.IsAttached()
+		}
+	}
+
+	req := &UpdateSessionsRequest{Sessions: []SessionUpdate{}}
+	for _, l := range listed {
+		if l.Exited {
+			continue
+		}
+		update := SessionUpdate{
+			Name:             l.Name,
+			IsCurrentSession: l.Current,
+			CreatedAt:        l.CreatedAt,
+		}
+		if l.Current || attached[l.Name] {
+			update.ConnectedClients = 1
+		}
+		req.Sessions = append(req.Sessions, update)
+	}
+
+	// The next plugin update must be applied even if it repeats the last one
+	z.lastApplied = [sha256.Size]byte{}
+	z.instances.Invalidate()
+	return z.applySessionUpdate(ctx, req)
+}
+
+func (z *ZellijService) applySessionUpdate(ctx context.Context, req *UpdateSessionsRequest) error {
+	stored, err := z.sessionService.ListSessions(ctx)
 	if err != nil {
 		return err
 	}
 
-	for _, existingSession := range allSessions {
-		sess := existingSession
+	now := time.Now()
+	inferWorkspaceID := func(cwd string) string {
+		return z.sessionService.InferWorkspaceID(ctx, cwd)
+	}
 
-		if update, exists := activeSessions[sess.ID]; exists {
-			sess.IsAttached = update.IsCurrentSession
-			sess.IsActive = true
-			sess.IsDead = false
-			sess.LastUsedAt = time.Now()
-			delete(activeSessions, sess.ID)
-		} else {
-			sess.IsDead = true
+	diff := diffSessions(stored, req.Sessions, now, inferWorkspaceID)
+
+	// Instances in sessions that are gone went with them
+	running := make(map[string]bool, len(req.Sessions))
+	for _, sessionUpdate := range req.Sessions {
+		running[sessionUpdate.Name] = true
+	}
+	for _, id := range z.instances.Retain(running) {
+		if z.poller != nil {
+			z.poller.Forget(id)
 		}
+	}
 
-		if err := z.sessionService.UpdateSession(ctx, &sess); err != nil {
+	for i := range diff.Update {
+		if err := z.sessionService.UpdateSession(ctx, &diff.Update[i]); err != nil {
 			return err
 		}
 	}
-
-	for sessionID, sessionUpdate := range activeSessions {
-		newSession := &session.Session{
-			ID:          sessionID,
-			WorkspaceID: "ws-1",
-			IsAttached:  sessionUpdate.IsCurrentSession,
-			IsActive:    true,
-			IsDead:      false,
-			LastUsedAt:  time.Now(),
+	for i := range diff.Create {
+		if err := z.sessionService.CreateSession(ctx, &diff.Create[i]); err != nil {
+			return err
 		}
+	}
 
-		if err := z.sessionService.CreateSession(ctx, newSession); err != nil {
+	for _, sessionUpdate := range req.Sessions {
+		if !sessionUpdate.hasSnapshot() {
+			continue
+		}
+		if err := z.sessionService.SetSnapshot(ctx, sessionUpdate.Name, sessionUpdate.snapshot(now)); err != nil {
 			return err
 		}
 	}
@@ -78,45 +361,192 @@ func (z *ZellijService) ProcessSessionUpdate(ctx context.Context, req *UpdateSes
 	return nil
 }
 
-func (z *ZellijService) handleSessionCreateRequested(ctx context.Context, event eventbus.Event) error {
-	data, ok := event.Data.(eventbus.SessionCreateRequestedEvent)
-	if !ok {
+func (z *ZellijService) handleSessionCreateRequested(ctx context.Context, data eventbus.SessionCreateRequestedEvent) error {
+	cmd := createSessionCommand(data.SessionName, data.WorkspacePath)
+	cmd.ID = data.CommandID
+	if err := z.dispatch(ctx, cmd); err != nil {
+		return err
+	}
+
+	// A plugin update may already have moved the session past requested
+	sess, err := z.sessionService.GetSession(ctx, data.SessionName)
+	if err != nil || sess.State != session.StateRequested {
 		return nil
 	}
-	return z.CreateSession(data.SessionName, data.WorkspacePath)
+
+	// The command was sent, so neither a retry nor a redrive may run this
+	// handler again: that would open a second session. The next plugin
+	// update moves the session along anyway.
+	if _, err := z.sessionService.TransitionSession(ctx, data.SessionName, session.StateStarting); err != nil {
+		log.Printf("Failed to mark session %s as starting: %v", data.SessionName, err)
+	}
+	return nil
 }
 
-func (z *ZellijService) sendCommandToPlugin(command Command) error {
-	return z.pipeSender.SendCommand(command)
+func (z *ZellijService) handleSessionActivateRequested(ctx context.Context, data eventbus.SessionActivateRequestedEvent) error {
+	cmd := switchSessionCommand(data.SessionName)
+	cmd.ID = data.CommandID
+	return z.dispatch(ctx, cmd)
+}
+
+// GetCommand returns the status of a command sent to the plugin.
+func (z *ZellijService) GetCommand(ctx context.Context, id string) (CommandStatus, error) {
+	return z.commands.Get(id)
+}
+
+// WaitForCommand blocks until the plugin reports the command's outcome, it
+// times out, or ctx ends.
+func (z *ZellijService) WaitForCommand(ctx context.Context, id string) (CommandStatus, error) {
+	return z.commands.Wait(ctx, id)
+}
+
+// PollCommands waits for commands for a plugin instance when the poll
+// transport is in use.
+func (z *ZellijService) PollCommands(ctx context.Context, instance string) ([]Command, error) {
+	if z.poller == nil {
+		return nil, ErrPollingDisabled
+	}
+
+	return z.poller.Poll(ctx, instance), nil
 }
 
-func (z *ZellijService) OpenPicker() error {
+// CompleteCommand records the outcome the plugin reported for a command. With
+// the poll transport, the report also acknowledges delivery.
+func (z *ZellijService) CompleteCommand(ctx context.Context, id string, result *CommandResultRequest) (CommandStatus, error) {
+	if z.poller != nil {
+		z.poller.Ack(id)
+	}
+
+	var cmdErr error
+	if !result.Success {
+		cmdErr = errors.New(result.Error)
+	}
+	return z.commands.Complete(id, cmdErr)
+}
+
+// sendCommandToPlugin tracks the command until the plugin reports back. A
+// failed send fails the command right away.
+func (z *ZellijService) sendCommandToPlugin(ctx context.Context, command Command) error {
+	if command.ID == "" {
+		command.ID = common.NewID()
+	}
+
+	if err := z.dispatch(ctx, command); err != nil {
+		z.commands.Complete(command.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// dispatch tracks the command and delivers it. A failed delivery leaves the
+// command pending: event handlers may still be retried, and failCommand
+// settles it once they give up.
+func (z *ZellijService) dispatch(ctx context.Context, command Command) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(z.lifetime, cancel)
+	defer stop()
+
+	version, ok := commandVersions[command.Command]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", ErrUnsupportedCommand, command.Command)
+	}
+	command.Version = version
+
+	z.commands.Track(command)
+	return z.deliver(ctx, command)
+}
+
+// deliver sends the command to the plugin instance running in the attached
+// session, since that is the client the user is looking at. Without a known
+// instance it goes to whichever one the sender reaches. A command the plugin
+// can't execute is refused rather than sent, and isn't worth retrying.
+func (z *ZellijService) deliver(ctx context.Context, command Command) error {
+	instance, targetKnown := z.commandTarget(ctx)
+	if targetKnown && !instance.Supports(command) {
+		return eventbus.Permanent(fmt.Errorf("%w: %s needs protocol %d, instance %s speaks %d with %v",
+			ErrUnsupportedCommand, command.Command, command.Version, instance.ID, instance.ProtocolVersion, instance.Commands))
+	}
+	if !targetKnown && !z.anyInstanceSupports(command) {
+		return eventbus.Permanent(fmt.Errorf("%w: no plugin instance supports %s at protocol %d",
+			ErrUnsupportedCommand, command.Command, command.Version))
+	}
+
+	targeted, ok := z.sender.(TargetedSender)
+	if ok && targetKnown {
+		return targeted.SendCommandTo(ctx, instance, command)
+	}
+	return z.sender.SendCommand(ctx, command)
+}
+
+// anyInstanceSupports reports whether an untargeted send could reach a plugin
+// able to execute the command. With no instances known there is nothing to
+// go on, so the send is attempted.
+func (z *ZellijService) anyInstanceSupports(command Command) bool {
+	instances := z.instances.List()
+	if len(instances) == 0 {
+		return true
+	}
+	for _, instance := range instances {
+		if instance.Supports(command) {
+			return true
+		}
+	}
+	return false
+}
+
+func (z *ZellijService) commandTarget(ctx context.Context) (PluginInstance, bool) {
+	sessions, err := z.sessionService.ListSessions(ctx)
+	if err != nil {
+		return PluginInstance{}, false
+	}
+
+	// Sessions are listed most recently used first
+	for _, sess := range sessions {
+		if !sess.IsAttached() {
+			continue
+		}
+		if instance, ok := z.instances.ForSession(sess.ID); ok {
+			return instance, true
+		}
+	}
+	return PluginInstance{}, false
+}
+
+func (z *ZellijService) OpenPicker(ctx context.Context) error {
 	cmd := Command{
 		Command: "open_picker",
 	}
-	return z.sendCommandToPlugin(cmd)
+	return z.sendCommandToPlugin(ctx, cmd)
+}
+
+func (z *ZellijService) SwitchSession(ctx context.Context, sessionName string) error {
+	return z.sendCommandToPlugin(ctx, switchSessionCommand(sessionName))
+}
+
+func (z *ZellijService) CreateSession(ctx context.Context, sessionName, workspacePath string) error {
+	return z.sendCommandToPlugin(ctx, createSessionCommand(sessionName, workspacePath))
 }
 
-func (z *ZellijService) SwitchSession(sessionName string) error {
+func (z *ZellijService) ClosePicker(ctx context.Context) error {
 	cmd := Command{
+		Command: "close_picker",
+	}
+	return z.sendCommandToPlugin(ctx, cmd)
+}
+
+func switchSessionCommand(sessionName string) Command {
+	return Command{
 		Command:     "switch_session",
 		SessionName: &sessionName,
 	}
-	return z.sendCommandToPlugin(cmd)
 }
 
-func (z *ZellijService) CreateSession(sessionName, workspacePath string) error {
-	cmd := Command{
+func createSessionCommand(sessionName, workspacePath string) Command {
+	return Command{
 		Command:       "create_session",
 		SessionName:   &sessionName,
 		WorkspacePath: &workspacePath,
 	}
-	return z.sendCommandToPlugin(cmd)
-}
-
-func (z *ZellijService) ClosePicker() error {
-	cmd := Command{
-		Command: "close_picker",
-	}
-	return z.sendCommandToPlugin(cmd)
 }
@@ -2,16 +2,19 @@ package zellij
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/eleonorayaya/utena/internal/config"
 	"github.com/eleonorayaya/utena/internal/eventbus"
 	"github.com/eleonorayaya/utena/internal/session"
 	"github.com/eleonorayaya/utena/internal/workspace"
 	"github.com/stretchr/testify/require"
 )
 
-func setupZellijService(t *testing.T) (*ZellijService, *session.SessionService, *session.SessionStore) {
+func setupZellijService(t *testing.T, opts ...ZellijOption) (*ZellijService, *session.SessionService, *session.SessionStore) {
 	t.Helper()
 
 	ctx := context.Background()
@@ -19,21 +22,37 @@ func setupZellijService(t *testing.T) (*ZellijService, *session.SessionService,
 	bus := eventbus.NewEventBus()
 	sessionStore := session.NewSessionStore()
 	workspaceStore := workspace.NewWorkspaceStore()
+	workspaceService := workspace.NewWorkspaceService(workspaceStore, workspace.NewWorkspaceDiscovery(), bus)
 
-	err := workspaceStore.OnAppStart(ctx)
+	err := workspaceService.OnAppStart(ctx)
 	require.NoError(t, err)
 
-	sessionService := session.NewSessionService(sessionStore, workspaceStore, bus)
+	err = workspaceStore.Add(&workspace.Workspace{ID: "ws-1", Name: "utena", Path: "/tmp/utena"})
+	require.NoError(t, err)
+
+	sessionService := session.NewSessionService(sessionStore, workspaceStore, session.NewSessionNamer(config.Default()), bus)
 	err = sessionService.OnAppStart(ctx)
 	require.NoError(t, err)
 
-	zellijService := NewZellijService(sessionService, bus)
+	// Commands are recorded instead of piped to zellij unless a test picks
+	// another sender
+	opts = append([]ZellijOption{WithCommandSender(NewRecordingSender())}, opts...)
+
+	zellijService := NewZellijService(sessionService, bus, opts...)
 	err = zellijService.OnAppStart(ctx)
 	require.NoError(t, err)
 
 	return zellijService, sessionService, sessionStore
 }
 
+func recordingSender(t *testing.T, service *ZellijService) *RecordingSender {
+	t.Helper()
+
+	sender, ok := service.sender.(*RecordingSender)
+	require.True(t, ok, "service is not using a RecordingSender")
+	return sender
+}
+
 func TestZellijService_ProcessSessionUpdate_CreateNewSessions(t *testing.T) {
 	service, _, sessionStore := setupZellijService(t)
 	ctx := context.Background()
@@ -43,6 +62,7 @@ func TestZellijService_ProcessSessionUpdate_CreateNewSessions(t *testing.T) {
 			{
 				Name:             "session-1",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 			{
 				Name:             "session-2",
@@ -60,17 +80,17 @@ func TestZellijService_ProcessSessionUpdate_CreateNewSessions(t *testing.T) {
 	session1, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
 	require.Equal(t, "session-1", session1.ID)
-	require.True(t, session1.IsAttached)
-	require.True(t, session1.IsActive)
-	require.False(t, session1.IsDead)
-	require.Equal(t, "ws-1", session1.WorkspaceID)
+	require.True(t, session1.IsAttached())
+	require.True(t, session1.IsActive())
+	require.False(t, session1.IsDead())
+	require.Equal(t, workspace.UnassignedWorkspaceID, session1.WorkspaceID)
 
 	session2, err := sessionStore.GetByID("session-2")
 	require.NoError(t, err)
 	require.Equal(t, "session-2", session2.ID)
-	require.False(t, session2.IsAttached)
-	require.True(t, session2.IsActive)
-	require.False(t, session2.IsDead)
+	require.False(t, session2.IsAttached())
+	require.True(t, session2.IsActive())
+	require.False(t, session2.IsDead())
 }
 
 func TestZellijService_ProcessSessionUpdate_UpdateExistingSessions(t *testing.T) {
@@ -81,9 +101,7 @@ func TestZellijService_ProcessSessionUpdate_UpdateExistingSessions(t *testing.T)
 	existingSession := &session.Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    false,
-		IsDead:      false,
+		State:       session.StateRequested,
 		LastUsedAt:  oldTime,
 	}
 	sessionStore.Add(existingSession)
@@ -93,6 +111,7 @@ func TestZellijService_ProcessSessionUpdate_UpdateExistingSessions(t *testing.T)
 			{
 				Name:             "session-1",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 		},
 	}
@@ -104,9 +123,9 @@ func TestZellijService_ProcessSessionUpdate_UpdateExistingSessions(t *testing.T)
 
 	updatedSession, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
-	require.True(t, updatedSession.IsAttached)
-	require.True(t, updatedSession.IsActive)
-	require.False(t, updatedSession.IsDead)
+	require.True(t, updatedSession.IsAttached())
+	require.True(t, updatedSession.IsActive())
+	require.False(t, updatedSession.IsDead())
 	require.True(t, updatedSession.LastUsedAt.After(oldTime))
 }
 
@@ -117,9 +136,7 @@ func TestZellijService_ProcessSessionUpdate_MixedCreateAndUpdate(t *testing.T) {
 	existingSession := &session.Session{
 		ID:          "existing-session",
 		WorkspaceID: "ws-1",
-		IsAttached:  false,
-		IsActive:    false,
-		IsDead:      false,
+		State:       session.StateRequested,
 		LastUsedAt:  time.Now().Add(-1 * time.Hour),
 	}
 	sessionStore.Add(existingSession)
@@ -129,6 +146,7 @@ func TestZellijService_ProcessSessionUpdate_MixedCreateAndUpdate(t *testing.T) {
 			{
 				Name:             "existing-session",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 			{
 				Name:             "new-session",
@@ -145,22 +163,77 @@ func TestZellijService_ProcessSessionUpdate_MixedCreateAndUpdate(t *testing.T) {
 
 	updated, err := sessionStore.GetByID("existing-session")
 	require.NoError(t, err)
-	require.True(t, updated.IsAttached)
-	require.True(t, updated.IsActive)
-	require.False(t, updated.IsDead)
+	require.True(t, updated.IsAttached())
+	require.True(t, updated.IsActive())
+	require.False(t, updated.IsDead())
 
 	new, err := sessionStore.GetByID("new-session")
 	require.NoError(t, err)
-	require.False(t, new.IsAttached)
-	require.True(t, new.IsActive)
-	require.False(t, new.IsDead)
+	require.False(t, new.IsAttached())
+	require.True(t, new.IsActive())
+	require.False(t, new.IsDead())
 }
 
 func TestZellijService_CreateSession(t *testing.T) {
 	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	err := service.CreateSession(ctx, "new-session", "/tmp/workspace")
+	require.NoError(t, err)
+
+	commands := recordingSender(t, service).Commands()
+	require.Len(t, commands, 1)
+	require.Equal(t, "create_session", commands[0].Command)
+	require.Equal(t, "new-session", *commands[0].SessionName)
+	require.Equal(t, "/tmp/workspace", *commands[0].WorkspacePath)
+	require.NotEmpty(t, commands[0].ID)
+
+	status, err := service.GetCommand(ctx, commands[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, CommandPending, status.State)
+}
+
+func TestZellijService_CreateSession_SendFails(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	sender := recordingSender(t, service)
+	sender.FailWith(errors.New("zellij pipe failed"))
 
-	err := service.CreateSession("new-session", "/tmp/workspace")
+	err := service.CreateSession(ctx, "new-session", "/tmp/workspace")
 	require.Error(t, err)
+
+	status, err := service.GetCommand(ctx, sender.Commands()[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, CommandFailed, status.State)
+	require.Equal(t, "zellij pipe failed", status.Error)
+}
+
+func TestZellijService_SessionCreateRequested_SendsCommand(t *testing.T) {
+	service, sessionService, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "new-session", WorkspaceID: "ws-1", State: session.StateRequested, LastUsedAt: time.Now()})
+
+	err := eventbus.Publish(ctx, service.eventBus, eventbus.SessionCreateRequestedEvent{
+		CommandID:     "cmd-1",
+		SessionName:   "new-session",
+		WorkspaceID:   "ws-1",
+		WorkspacePath: "/tmp/utena",
+	})
+	require.NoError(t, err)
+
+	commands := recordingSender(t, service).Commands()
+	require.Len(t, commands, 1)
+	require.Equal(t, "cmd-1", commands[0].ID)
+	require.Equal(t, "create_session", commands[0].Command)
+	require.Equal(t, "new-session", *commands[0].SessionName)
+	require.Equal(t, "/tmp/utena", *commands[0].WorkspacePath)
+
+	// Once the command is out the session waits on zellij
+	created, err := sessionService.GetSession(ctx, "new-session")
+	require.NoError(t, err)
+	require.Equal(t, session.StateStarting, created.State)
 }
 
 func TestZellijService_ProcessSessionUpdate_MarkDeadSessions(t *testing.T) {
@@ -170,22 +243,19 @@ func TestZellijService_ProcessSessionUpdate_MarkDeadSessions(t *testing.T) {
 	sess1 := &session.Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sess2 := &session.Session{
 		ID:          "session-2",
 		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sess3 := &session.Session{
 		ID:          "session-3",
 		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(sess1)
@@ -197,6 +267,7 @@ func TestZellijService_ProcessSessionUpdate_MarkDeadSessions(t *testing.T) {
 			{
 				Name:             "session-1",
 				IsCurrentSession: true,
+				ConnectedClients: 1,
 			},
 			{
 				Name:             "session-3",
@@ -210,17 +281,17 @@ func TestZellijService_ProcessSessionUpdate_MarkDeadSessions(t *testing.T) {
 
 	updated1, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
-	require.False(t, updated1.IsDead)
-	require.True(t, updated1.IsActive)
+	require.False(t, updated1.IsDead())
+	require.True(t, updated1.IsActive())
 
 	updated2, err := sessionStore.GetByID("session-2")
 	require.NoError(t, err)
-	require.True(t, updated2.IsDead)
+	require.True(t, updated2.IsDead())
 
 	updated3, err := sessionStore.GetByID("session-3")
 	require.NoError(t, err)
-	require.False(t, updated3.IsDead)
-	require.True(t, updated3.IsActive)
+	require.False(t, updated3.IsDead())
+	require.True(t, updated3.IsActive())
 }
 
 func TestZellijService_ProcessSessionUpdate_AllSessionsDead(t *testing.T) {
@@ -230,8 +301,7 @@ func TestZellijService_ProcessSessionUpdate_AllSessionsDead(t *testing.T) {
 	sess1 := &session.Session{
 		ID:          "session-1",
 		WorkspaceID: "ws-1",
-		IsActive:    true,
-		IsDead:      false,
+		State:       session.StateRunningDetached,
 		LastUsedAt:  time.Now(),
 	}
 	sessionStore.Add(sess1)
@@ -245,5 +315,651 @@ func TestZellijService_ProcessSessionUpdate_AllSessionsDead(t *testing.T) {
 
 	updated, err := sessionStore.GetByID("session-1")
 	require.NoError(t, err)
-	require.True(t, updated.IsDead)
+	require.True(t, updated.IsDead())
+}
+
+func TestZellijService_ActivateSessionSwitchesZellij(t *testing.T) {
+	service, sessionService, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{
+		ID:          "session-1",
+		WorkspaceID: "ws-1",
+		State:       session.StateRunningDetached,
+		LastUsedAt:  time.Now(),
+	})
+
+	activation, err := sessionService.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+
+	commands := recordingSender(t, service).Commands()
+	require.Len(t, commands, 1)
+	require.Equal(t, activation.CommandID, commands[0].ID)
+	require.Equal(t, "switch_session", commands[0].Command)
+	require.Equal(t, "session-1", *commands[0].SessionName)
+}
+
+func TestZellijService_ActivateSession_SendFails(t *testing.T) {
+	service, sessionService, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+	recordingSender(t, service).FailWith(errors.New("zellij pipe failed"))
+
+	activation, err := sessionService.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+	require.ErrorContains(t, activation.Err, "zellij pipe failed")
+
+	status, err := service.GetCommand(ctx, activation.CommandID)
+	require.NoError(t, err)
+	require.Equal(t, CommandFailed, status.State)
+	require.Contains(t, status.Error, "zellij pipe failed")
+}
+
+// flakySender fails the first failures sends, passing each failed command's
+// ID to failed.
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	failed   chan string
+}
+
+func (s *flakySender) SendCommand(ctx context.Context, cmd Command) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures == 0 {
+		return nil
+	}
+	s.failures--
+	s.failed <- cmd.ID
+	return errors.New("zellij pipe failed")
+}
+
+func TestZellijService_CommandStaysPendingBetweenRetries(t *testing.T) {
+	sender := &flakySender{failures: 1, failed: make(chan string, 1)}
+	policy := eventbus.RetryPolicy{Attempts: 2, InitialBackoff: 200 * time.Millisecond}
+	service, sessionService, sessionStore := setupZellijService(t, WithCommandSender(sender), WithRetry(policy))
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := sessionService.ActivateSession(ctx, "session-1")
+		errs <- err
+	}()
+
+	// A caller waiting on the command doesn't see the transient failure
+	id := <-sender.failed
+	require.Never(t, func() bool {
+		status, err := service.GetCommand(ctx, id)
+		return err != nil || status.State != CommandPending
+	}, 100*time.Millisecond, 5*time.Millisecond)
+
+	require.NoError(t, <-errs)
+	status, err := service.GetCommand(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, CommandPending, status.State)
+}
+
+func TestZellijService_CommandFailsOnceRetriesAreExhausted(t *testing.T) {
+	sender := &flakySender{failures: 2, failed: make(chan string, 2)}
+	policy := eventbus.RetryPolicy{Attempts: 2, InitialBackoff: time.Millisecond}
+	service, sessionService, sessionStore := setupZellijService(t, WithCommandSender(sender), WithRetry(policy))
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	activation, err := sessionService.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+	require.Error(t, activation.Err)
+
+	status, err := service.GetCommand(ctx, activation.CommandID)
+	require.NoError(t, err)
+	require.Equal(t, CommandFailed, status.State)
+	require.Contains(t, status.Error, "2 attempts: zellij pipe failed")
+}
+
+func TestZellijService_ProcessSessionUpdate_InfersWorkspaceFromCwd(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	req := &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{
+			{
+				Name:             "in-workspace",
+				IsCurrentSession: true,
+				ConnectedClients: 1,
+				Cwd:              "/tmp/utena/internal/zellij",
+			},
+			{
+				Name:             "elsewhere",
+				IsCurrentSession: false,
+				Cwd:              "/var/log",
+			},
+		},
+	}
+
+	err := service.ProcessSessionUpdate(ctx, req)
+	require.NoError(t, err)
+
+	inWorkspace, err := sessionStore.GetByID("in-workspace")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", inWorkspace.WorkspaceID)
+
+	elsewhere, err := sessionStore.GetByID("elsewhere")
+	require.NoError(t, err)
+	require.Equal(t, workspace.UnassignedWorkspaceID, elsewhere.WorkspaceID)
+}
+
+func TestZellijService_ProcessSessionUpdate_AssignsUnassignedSessionOnceCwdIsKnown(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	// First seen without a cwd, e.g. from another session's plugin instance
+	err := service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1"}},
+	})
+	require.NoError(t, err)
+
+	sess, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, workspace.UnassignedWorkspaceID, sess.WorkspaceID)
+
+	err = service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1, Cwd: "/tmp/utena"}},
+	})
+	require.NoError(t, err)
+
+	sess, err = sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", sess.WorkspaceID)
+
+	// An explicit assignment is not overridden by a later cwd
+	err = service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1, Cwd: "/var/log"}},
+	})
+	require.NoError(t, err)
+
+	sess, err = sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, "ws-1", sess.WorkspaceID)
+}
+
+func TestZellijService_ProcessSessionUpdate_KeepsPendingSessions(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "requested", WorkspaceID: "ws-1", State: session.StateRequested, LastUsedAt: time.Now()})
+	sessionStore.Add(&session.Session{ID: "starting", WorkspaceID: "ws-1", State: session.StateStarting, LastUsedAt: time.Now()})
+	sessionStore.Add(&session.Session{ID: "archived", WorkspaceID: "ws-1", State: session.StateArchived, LastUsedAt: time.Now()})
+
+	err := service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{Sessions: []SessionUpdate{}})
+	require.NoError(t, err)
+
+	// Zellij may not have created them yet, so they aren't marked exited
+	requested, err := sessionStore.GetByID("requested")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRequested, requested.State)
+
+	starting, err := sessionStore.GetByID("starting")
+	require.NoError(t, err)
+	require.Equal(t, session.StateStarting, starting.State)
+
+	archived, err := sessionStore.GetByID("archived")
+	require.NoError(t, err)
+	require.Equal(t, session.StateArchived, archived.State)
+}
+
+func TestZellijService_ProcessSessionUpdate_AttachAndDetach(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: time.Now()})
+
+	err := service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: false}},
+	})
+	require.NoError(t, err)
+
+	updated, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRunningDetached, updated.State)
+	require.False(t, updated.StateChangedAt[session.StateRunningDetached].IsZero())
+}
+
+func TestZellijService_ProcessSessionUpdate_RepeatedUpdatePublishesNothing(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	var published []string
+	for _, eventType := range []string{eventbus.SessionCreated, eventbus.SessionStateChanged, eventbus.SessionAttached, eventbus.SessionDied} {
+		service.eventBus.Subscribe(eventType, func(ctx context.Context, event eventbus.Event) error {
+			published = append(published, event.Type)
+			return nil
+		})
+	}
+
+	req := &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1}},
+	}
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+	require.Equal(t, []string{eventbus.SessionCreated}, published)
+
+	// The plugin resends the same snapshot
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+	require.Equal(t, []string{eventbus.SessionCreated}, published)
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{}))
+	require.Equal(t, []string{eventbus.SessionCreated, eventbus.SessionStateChanged, eventbus.SessionDied}, published)
+}
+
+func TestZellijService_OnAppEnd_Unsubscribes(t *testing.T) {
+	service, sessionService, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	require.NoError(t, service.OnAppEnd(ctx))
+
+	// Nothing forwards the switch to the plugin anymore
+	_, err := sessionService.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+	require.Empty(t, recordingSender(t, service).Commands())
+}
+
+// blockingSender never delivers, like `zellij pipe` with no plugin reading.
+type blockingSender struct{}
+
+func (blockingSender) SendCommand(ctx context.Context, cmd Command) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestZellijService_OnAppEnd_CancelsSends(t *testing.T) {
+	service, _, _ := setupZellijService(t, WithCommandSender(blockingSender{}))
+	ctx := context.Background()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- service.SwitchSession(ctx, "session-1")
+	}()
+
+	// Give the send a moment to start before shutting down
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, service.OnAppEnd(ctx))
+
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("send was not cancelled on shutdown")
+	}
+}
+
+func TestZellijService_PollCommands_Disabled(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+
+	_, err := service.PollCommands(context.Background(), "plugin-1")
+	require.ErrorIs(t, err, ErrPollingDisabled)
+}
+
+func TestZellijService_PollTransport(t *testing.T) {
+	service, sessionService, sessionStore := setupZellijService(t, WithCommandSender(NewPollSender(time.Minute)))
+
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: time.Now()})
+
+	ctx := context.Background()
+	activation, err := sessionService.ActivateSession(ctx, "session-1")
+	require.NoError(t, err)
+
+	pollCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	commands, err := service.PollCommands(pollCtx, "plugin-1")
+	require.NoError(t, err)
+	require.Len(t, commands, 1)
+	require.Equal(t, activation.CommandID, commands[0].ID)
+	require.Equal(t, "switch_session", commands[0].Command)
+	require.Equal(t, "session-1", *commands[0].SessionName)
+
+	// Reporting the result acks the delivery
+	status, err := service.CompleteCommand(ctx, activation.CommandID, &CommandResultRequest{Success: true})
+	require.NoError(t, err)
+	require.Equal(t, CommandSucceeded, status.State)
+	require.Zero(t, service.poller.shared.Len())
+}
+
+func TestZellijService_ProcessSessionUpdate_StoresSnapshot(t *testing.T) {
+	service, sessionService, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	exitStatus := 1
+	req := &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{
+			{
+				Name:             "session-1",
+				IsCurrentSession: true,
+				Cwd:              "/tmp/utena",
+				ConnectedClients: 2,
+				Tabs:             []TabInfo{{Position: 0, Name: "editor", Active: true}},
+				Panes: []PaneInfo{
+					{ID: 1, Title: "nvim", TerminalCommand: "nvim", IsFocused: true},
+					{ID: 2, Title: "tests", TerminalCommand: "go test ./...", Exited: true, ExitStatus: &exitStatus},
+					{ID: 0, Title: "utena", IsPlugin: true, PluginURL: "file:utena.wasm", TerminalCommand: "ignored"},
+				},
+			},
+			// An older plugin that doesn't send snapshots
+			{Name: "session-2"},
+		},
+	}
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+
+	snapshot, err := sessionService.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Equal(t, 2, snapshot.ConnectedClients)
+	require.Equal(t, "/tmp/utena", snapshot.Cwd)
+	require.Equal(t, []session.Tab{{Position: 0, Name: "editor", Active: true}}, snapshot.Tabs)
+	require.Len(t, snapshot.Panes, 3)
+	require.Equal(t, "nvim", snapshot.Panes[0].Command)
+	require.Equal(t, &exitStatus, snapshot.Panes[1].ExitStatus)
+	require.Equal(t, "file:utena.wasm", snapshot.Panes[2].Command)
+
+	snapshot, err = sessionService.GetSnapshot(ctx, "session-2")
+	require.NoError(t, err)
+	require.Empty(t, snapshot.Panes)
+
+	// Listing from the CLI carries no snapshot, so the last one is kept
+	require.NoError(t, service.ReconcileSessions(ctx, []ListedSession{{Name: "session-1", Current: true}}))
+	snapshot, err = sessionService.GetSnapshot(ctx, "session-1")
+	require.NoError(t, err)
+	require.Len(t, snapshot.Tabs, 1)
+}
+
+func TestZellijService_ProcessSessionUpdate_KeepsRecencyOfOtherSessions(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	earlier := time.Now().Add(-time.Hour)
+	sessionStore.Add(&session.Session{ID: "session-1", WorkspaceID: "ws-1", State: session.StateAttached, LastUsedAt: earlier})
+	sessionStore.Add(&session.Session{ID: "session-2", WorkspaceID: "ws-1", State: session.StateRunningDetached, LastUsedAt: earlier.Add(-time.Hour)})
+
+	err := service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1}, {Name: "session-2"}},
+	})
+	require.NoError(t, err)
+
+	// Neither session became current, so MRU order is untouched
+	sessions := sessionStore.List()
+	require.Equal(t, "session-1", sessions[0].ID)
+	require.Equal(t, earlier, sessions[0].LastUsedAt)
+	require.Equal(t, earlier.Add(-time.Hour), sessions[1].LastUsedAt)
+}
+
+func TestZellijService_ProcessSessionUpdate_CoalescesIdenticalUpdates(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t, WithUpdateCoalescing(time.Minute))
+	ctx := context.Background()
+
+	req := &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1}},
+	}
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+
+	// Change the stored session behind the plugin's back; a repeat of the
+	// same report inside the window isn't applied to correct it
+	detached, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	changed := *detached
+	changed.State = session.StateRunningDetached
+	require.NoError(t, sessionStore.Update(&changed))
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+	sess, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateRunningDetached, sess.State)
+
+	// A different report is applied right away
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1}, {Name: "session-2"}},
+	}))
+	sess, err = sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateAttached, sess.State)
+	require.Len(t, sessionStore.List(), 2)
+}
+
+func TestZellijService_ProcessSessionUpdate_ReconcileBreaksCoalescing(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t, WithUpdateCoalescing(time.Minute))
+	ctx := context.Background()
+
+	req := &UpdateSessionsRequest{
+		Sessions: []SessionUpdate{{Name: "session-1", IsCurrentSession: true, ConnectedClients: 1}},
+	}
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+	require.NoError(t, service.ReconcileSessions(ctx, []ListedSession{{Name: "session-1"}}))
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, req))
+	sess, err := sessionStore.GetByID("session-1")
+	require.NoError(t, err)
+	require.Equal(t, session.StateAttached, sess.State)
+}
+
+// twoInstanceReports are what the plugin instances in utena-main, which a
+// client is attached to, and in the detached other session report. Each
+// marks its own host session as current.
+func twoInstanceReports() (fromMain, fromOther *UpdateSessionsRequest) {
+	fromMain = &UpdateSessionsRequest{
+		ID:      "plugin-main",
+		Version: "0.1.0",
+		Sessions: []SessionUpdate{
+			{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1},
+			{Name: "other"},
+		},
+	}
+	fromOther = &UpdateSessionsRequest{
+		ID: "plugin-other",
+		Sessions: []SessionUpdate{
+			{Name: "utena-main", ConnectedClients: 1},
+			{Name: "other", IsCurrentSession: true},
+		},
+	}
+	return fromMain, fromOther
+}
+
+func TestZellijService_ProcessSessionUpdate_InstancesDontFlapStates(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	fromMain, fromOther := twoInstanceReports()
+	for _, report := range []*UpdateSessionsRequest{fromOther, fromMain, fromOther, fromMain, fromOther} {
+		require.NoError(t, service.ProcessSessionUpdate(ctx, report))
+
+		main, err := sessionStore.GetByID("utena-main")
+		require.NoError(t, err)
+		require.Equal(t, session.StateAttached, main.State)
+		other, err := sessionStore.GetByID("other")
+		require.NoError(t, err)
+		require.Equal(t, session.StateRunningDetached, other.State)
+	}
+
+	instances := service.ListInstances(ctx)
+	require.Len(t, instances, 2)
+	hosts := map[string]string{}
+	for _, instance := range instances {
+		hosts[instance.ID] = instance.HostSession
+	}
+	require.Equal(t, map[string]string{"plugin-main": "utena-main", "plugin-other": "other"}, hosts)
+
+	// The other session closes, taking its instance with it
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-main",
+		Sessions: []SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}},
+	}))
+	instances = service.ListInstances(ctx)
+	require.Len(t, instances, 1)
+	require.Equal(t, "plugin-main", instances[0].ID)
+	require.Equal(t, "0.1.0", instances[0].Version)
+}
+
+func TestZellijService_ProcessSessionUpdate_InterleavedInstancesKeepRecency(t *testing.T) {
+	service, _, sessionStore := setupZellijService(t)
+	ctx := context.Background()
+
+	fromMain, fromOther := twoInstanceReports()
+	require.NoError(t, service.ProcessSessionUpdate(ctx, fromMain))
+	main, err := sessionStore.GetByID("utena-main")
+	require.NoError(t, err)
+	other, err := sessionStore.GetByID("other")
+	require.NoError(t, err)
+	mainUsedAt, otherUsedAt := main.LastUsedAt, other.LastUsedAt
+
+	// Every report is applied, but none of them changes anything
+	for _, report := range []*UpdateSessionsRequest{fromOther, fromMain, fromOther, fromMain} {
+		require.NoError(t, service.ProcessSessionUpdate(ctx, report))
+
+		main, err := sessionStore.GetByID("utena-main")
+		require.NoError(t, err)
+		require.Equal(t, mainUsedAt, main.LastUsedAt)
+		other, err := sessionStore.GetByID("other")
+		require.NoError(t, err)
+		require.Equal(t, otherUsedAt, other.LastUsedAt)
+	}
+}
+
+func TestZellijService_RoutesCommandsToAttachedInstance(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+	sender := recordingSender(t, service)
+
+	// No instance known yet, so the command goes wherever the sender reaches
+	require.NoError(t, service.SwitchSession(ctx, "other"))
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-main",
+		Sessions: []SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}, {Name: "other"}},
+	}))
+	require.NoError(t, service.SwitchSession(ctx, "other"))
+
+	sent := sender.Sent()
+	require.Len(t, sent, 2)
+	require.Empty(t, sent[0].Instance)
+	require.Equal(t, "plugin-main", sent[1].Instance)
+	require.Equal(t, "switch_session", sent[1].Command.Command)
+}
+
+func TestZellijService_PollTransport_RoutesToInstance(t *testing.T) {
+	service, _, _ := setupZellijService(t, WithCommandSender(NewPollSender(time.Minute)))
+	ctx := context.Background()
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-main",
+		Sessions: []SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}, {Name: "other"}},
+	}))
+	require.NoError(t, service.SwitchSession(ctx, "other"))
+
+	// Only the instance in the attached session receives it
+	require.Empty(t, pollWithin(t, service.poller, "plugin-other", 10*time.Millisecond))
+	commands := pollWithin(t, service.poller, "plugin-main", time.Second)
+	require.Len(t, commands, 1)
+	require.Equal(t, "switch_session", commands[0].Command)
+}
+
+func TestZellijService_PollTransport_ReroutesCommandsOfGoneInstances(t *testing.T) {
+	service, _, _ := setupZellijService(t, WithCommandSender(NewPollSender(time.Minute)))
+	ctx := context.Background()
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-main",
+		Sessions: []SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}, {Name: "other"}},
+	}))
+	require.NoError(t, service.SwitchSession(ctx, "other"))
+
+	// utena-main closed before its plugin picked the command up
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-other",
+		Sessions: []SessionUpdate{{Name: "other", IsCurrentSession: true, ConnectedClients: 1}},
+	}))
+
+	commands := pollWithin(t, service.poller, "plugin-other", time.Second)
+	require.Len(t, commands, 1)
+	require.Equal(t, "switch_session", commands[0].Command)
+}
+
+func TestZellijService_Heartbeat(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	require.Equal(t, Disconnected, service.Status(ctx).Status)
+
+	status := service.Heartbeat(ctx, &HeartbeatRequest{ID: "plugin-1", Version: "0.1.0", HostSession: "utena-main"})
+	require.Equal(t, Connected, status.Status)
+	require.False(t, service.LastPluginUpdate().IsZero())
+
+	instances := service.ListInstances(ctx)
+	require.Len(t, instances, 1)
+	require.Equal(t, "utena-main", instances[0].HostSession)
+	require.Equal(t, "0.1.0", instances[0].Version)
+}
+
+func TestZellijService_SessionUpdateCountsAsContact(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{}))
+	require.Equal(t, Connected, service.Status(ctx).Status)
+}
+
+func TestZellijService_Register(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+
+	instance, err := service.Register(ctx, &RegisterRequest{
+		ID:              "plugin-1",
+		HostSession:     "utena-main",
+		ProtocolVersion: ProtocolVersion + 1,
+		Commands:        []string{"open_picker"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ProtocolVersion, instance.ProtocolVersion)
+	require.Equal(t, Connected, service.Status(ctx).Status)
+
+	_, err = service.Register(ctx, &RegisterRequest{ID: "plugin-2", ProtocolVersion: MinProtocolVersion - 1})
+	require.ErrorIs(t, err, ErrIncompatibleProtocol)
+	require.Len(t, service.ListInstances(ctx), 1)
+}
+
+func TestZellijService_RefusesUnsupportedCommands(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+	ctx := context.Background()
+	sender := recordingSender(t, service)
+
+	require.NoError(t, service.ProcessSessionUpdate(ctx, &UpdateSessionsRequest{
+		ID:       "plugin-main",
+		Sessions: []SessionUpdate{{Name: "utena-main", IsCurrentSession: true, ConnectedClients: 1}, {Name: "other"}},
+	}))
+	_, err := service.Register(ctx, &RegisterRequest{
+		ID:              "plugin-main",
+		HostSession:     "utena-main",
+		ProtocolVersion: ProtocolVersion,
+		Commands:        []string{"open_picker", "close_picker"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, service.OpenPicker(ctx))
+	err = service.SwitchSession(ctx, "other")
+	require.ErrorIs(t, err, ErrUnsupportedCommand)
+
+	// The refused command never reaches the plugin
+	commands := sender.Commands()
+	require.Len(t, commands, 1)
+	require.Equal(t, "open_picker", commands[0].Command)
+	require.Equal(t, 1, commands[0].Version)
+}
+
+func TestZellijService_RefusesUnknownCommands(t *testing.T) {
+	service, _, _ := setupZellijService(t)
+
+	err := service.sendCommandToPlugin(context.Background(), Command{Command: "rename_session"})
+	require.ErrorIs(t, err, ErrUnsupportedCommand)
+	require.Empty(t, recordingSender(t, service).Commands())
 }
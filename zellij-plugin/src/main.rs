@@ -7,26 +7,169 @@ use std::collections::BTreeMap;
 use std::path::PathBuf;
 use zellij_tile::prelude::*;
 
+const DAEMON_URL: &str = "http://localhost:3333";
+/// Must match `heartbeat_interval_ms` in the daemon config. The same timer
+/// retries failed polls.
+const HEARTBEAT_SECS: f64 = 10.0;
+const PLUGIN_VERSION: &str = env!("CARGO_PKG_VERSION");
+/// Must match `ProtocolVersion` in the daemon. Bump it whenever a request or
+/// command shape changes in a way an older daemon would misread.
+const PROTOCOL_VERSION: u32 = 1;
+const SUPPORTED_COMMANDS: &[&str] = &[
+    "open_picker",
+    "switch_session",
+    "create_session",
+    "close_picker",
+];
+
 #[derive(Default)]
 struct State {
     tui_open: bool,
+    /// Fetch commands with GET /zellij/commands instead of waiting for
+    /// `zellij pipe` messages. Set with `transport "poll"` in the plugin config.
+    poll_commands: bool,
+    poll_retry_pending: bool,
+    heartbeat_started: bool,
+    instance_id: String,
+    host_session: Option<String>,
+    /// Set once the daemon accepts the handshake. Until then the heartbeat
+    /// retries it.
+    registered: bool,
+    /// Set when the daemon refuses our protocol version, which only a
+    /// rebuild of one side can fix.
+    incompatible: bool,
 }
 
 #[derive(Serialize, Debug)]
 struct SessionUpdate {
     name: String,
     is_current_session: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    cwd: Option<String>,
+    tabs: Vec<TabSnapshot>,
+    panes: Vec<PaneSnapshot>,
+    connected_clients: usize,
+}
+
+#[derive(Serialize, Debug)]
+struct TabSnapshot {
+    position: usize,
+    name: String,
+    active: bool,
+}
+
+#[derive(Serialize, Debug)]
+struct PaneSnapshot {
+    id: u32,
+    tab_position: usize,
+    title: String,
+    is_plugin: bool,
+    is_focused: bool,
+    is_floating: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    terminal_command: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    plugin_url: Option<String>,
+    exited: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    exit_status: Option<i32>,
+}
+
+impl SessionUpdate {
+    fn from_session_info(session: &SessionInfo, current_cwd: &str) -> Self {
+        let tabs = session
+            .tabs
+            .iter()
+            .map(|tab| TabSnapshot {
+                position: tab.position,
+                name: tab.name.clone(),
+                active: tab.active,
+            })
+            .collect();
+
+        let panes = session
+            .panes
+            .panes
+            .iter()
+            .flat_map(|(tab_position, panes)| {
+                panes.iter().map(move |pane| PaneSnapshot {
+                    id: pane.id,
+                    tab_position: *tab_position,
+                    title: pane.title.clone(),
+                    is_plugin: pane.is_plugin,
+                    is_focused: pane.is_focused,
+                    is_floating: pane.is_floating,
+                    terminal_command: pane.terminal_command.clone(),
+                    plugin_url: pane.plugin_url.clone(),
+                    exited: pane.exited,
+                    exit_status: pane.exit_status,
+                })
+            })
+            .collect();
+
+        SessionUpdate {
+            name: session.name.clone(),
+            is_current_session: session.is_current_session,
+            cwd: session
+                .is_current_session
+                .then(|| current_cwd.to_string()),
+            tabs,
+            panes,
+            connected_clients: session.connected_clients,
+        }
+    }
 }
 
 #[derive(Serialize, Debug)]
 struct SessionUpdateRequest {
     id: String,
+    version: String,
     sessions: Vec<SessionUpdate>,
 }
 
+#[derive(Serialize, Debug)]
+struct Register {
+    id: String,
+    version: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    host_session: Option<String>,
+    protocol_version: u32,
+    commands: Vec<String>,
+}
+
+#[derive(Deserialize, Debug)]
+struct RegisterResponse {
+    protocol_version: u32,
+}
+
+#[derive(Serialize, Debug)]
+struct Heartbeat {
+    id: String,
+    version: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    host_session: Option<String>,
+}
+
+#[derive(Serialize, Debug)]
+struct CommandResult {
+    success: bool,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+#[derive(Deserialize, Debug)]
+struct CommandList {
+    commands: Vec<PluginCommand>,
+}
+
 #[derive(Deserialize, Debug)]
 struct PluginCommand {
+    #[serde(default)]
+    id: Option<String>,
     command: String,
+    /// The protocol version the command needs. Older daemons don't send it.
+    #[serde(default)]
+    version: u32,
     session_name: Option<String>,
     workspace_path: Option<String>,
 }
@@ -65,51 +208,212 @@ impl State {
     fn execute_command(&mut self, command: PluginCommand) {
         log_debug!("Executing command: {:?}", command);
 
+        let id = command.id.clone();
+        let result = self.run_command(command);
+        if let Err(e) = &result {
+            log_error!("{}", e);
+        }
+
+        if let Some(id) = id {
+            report_command_result(&id, result);
+        }
+    }
+
+    /// Long-polls the daemon for commands. The response arrives as a
+    /// WebRequestResult tagged with the "poll" request context.
+    fn poll_for_commands(&self) {
+        let mut context = BTreeMap::new();
+        context.insert("request".to_string(), "poll".to_string());
+        web_request(
+            format!(
+                "{}/zellij/commands?instance={}&timeout=25s",
+                DAEMON_URL, self.instance_id
+            ),
+            HttpVerb::Get,
+            BTreeMap::new(),
+            vec![],
+            context,
+        );
+    }
+
+    fn handle_poll_result(&mut self, status: u16, body: Vec<u8>) {
+        if status != 200 {
+            log_error!("Polling for commands failed with status {}", status);
+            self.poll_retry_pending = true;
+            return;
+        }
+
+        match serde_json::from_slice::<CommandList>(&body) {
+            Ok(list) => {
+                for command in list.commands {
+                    self.execute_command(command);
+                }
+            }
+            Err(e) => log_error!("Failed to parse polled commands: {}", e),
+        }
+
+        self.poll_for_commands();
+    }
+
+    /// Declares our protocol version and commands to the daemon. The response
+    /// arrives as a WebRequestResult tagged with the "register" context.
+    fn register(&self) {
+        let body = Register {
+            id: self.instance_id.clone(),
+            version: PLUGIN_VERSION.to_string(),
+            host_session: self.host_session.clone(),
+            protocol_version: PROTOCOL_VERSION,
+            commands: SUPPORTED_COMMANDS.iter().map(|c| c.to_string()).collect(),
+        };
+
+        let body = serde_json::to_vec(&body).unwrap();
+        let mut headers = BTreeMap::new();
+        headers.insert("Content-Type".to_string(), "application/json".to_string());
+        let mut context = BTreeMap::new();
+        context.insert("request".to_string(), "register".to_string());
+        web_request(
+            format!("{}/zellij/register", DAEMON_URL),
+            HttpVerb::Post,
+            headers,
+            body,
+            context,
+        );
+    }
+
+    fn handle_register_result(&mut self, status: u16, body: Vec<u8>) {
+        match status {
+            200 => match serde_json::from_slice::<RegisterResponse>(&body) {
+                Ok(resp) => {
+                    log_info!("Registered with daemon at protocol {}", resp.protocol_version);
+                    self.registered = true;
+                }
+                Err(e) => log_error!("Failed to parse register response: {}", e),
+            },
+            409 => {
+                log_error!(
+                    "Daemon rejected protocol {}: {}",
+                    PROTOCOL_VERSION,
+                    String::from_utf8_lossy(&body)
+                );
+                self.incompatible = true;
+            }
+            _ => log_error!("Registering with daemon failed with status {}", status),
+        }
+    }
+
+    /// Tells the daemon this instance is alive, then schedules the next tick.
+    /// Only one timer is ever pending since every Timer event lands here. It
+    /// also sends the handshake until the daemon has answered it.
+    fn heartbeat(&mut self) {
+        let body = Heartbeat {
+            id: self.instance_id.clone(),
+            version: PLUGIN_VERSION.to_string(),
+            host_session: self.host_session.clone(),
+        };
+
+        let body = serde_json::to_vec(&body).unwrap();
+        let mut headers = BTreeMap::new();
+        headers.insert("Content-Type".to_string(), "application/json".to_string());
+        let mut context = BTreeMap::new();
+        context.insert("request".to_string(), "heartbeat".to_string());
+        web_request(
+            format!("{}/zellij/heartbeat", DAEMON_URL),
+            HttpVerb::Post,
+            headers,
+            body,
+            context,
+        );
+
+        if !self.registered && !self.incompatible {
+            self.register();
+        }
+
+        if self.poll_retry_pending {
+            self.poll_retry_pending = false;
+            self.poll_for_commands();
+        }
+
+        set_timeout(HEARTBEAT_SECS);
+    }
+
+    fn run_command(&mut self, command: PluginCommand) -> Result<(), String> {
+        if command.version > PROTOCOL_VERSION {
+            return Err(format!(
+                "{} needs protocol {}, plugin speaks {}",
+                command.command, command.version, PROTOCOL_VERSION
+            ));
+        }
+
         match command.command.as_str() {
             "open_picker" => {
                 log_info!("Opening session picker via pipe command");
                 self.launch_session_picker();
+                Ok(())
             }
 
             "switch_session" => {
-                if let Some(session_name) = command.session_name {
-                    log_info!("Switching to session: {}", session_name);
-                    switch_session_with_cwd(Some(&session_name), None);
-                    self.tui_open = false;
-                } else {
-                    log_error!("switch_session missing session_name");
-                }
+                let session_name = command
+                    .session_name
+                    .ok_or("switch_session missing session_name")?;
+                log_info!("Switching to session: {}", session_name);
+                switch_session_with_cwd(Some(&session_name), None);
+                self.tui_open = false;
+                Ok(())
             }
 
             "create_session" => {
-                if let (Some(session_name), Some(workspace_path)) =
+                let (Some(session_name), Some(workspace_path)) =
                     (command.session_name, command.workspace_path)
-                {
-                    log_info!("Creating session: {} at {}", session_name, workspace_path);
-                    let cwd = PathBuf::from(workspace_path);
-                    switch_session_with_cwd(Some(&session_name), Some(cwd));
-                    self.tui_open = false;
-                } else {
-                    log_error!("create_session missing required fields");
-                }
+                else {
+                    return Err("create_session missing required fields".to_string());
+                };
+                log_info!("Creating session: {} at {}", session_name, workspace_path);
+                let cwd = PathBuf::from(workspace_path);
+                switch_session_with_cwd(Some(&session_name), Some(cwd));
+                self.tui_open = false;
+                Ok(())
             }
 
             "close_picker" => {
                 log_info!("Closing session picker");
                 self.tui_open = false;
+                Ok(())
             }
 
-            _ => {
-                log_warn!("Unknown command: {}", command.command);
-            }
+            _ => Err(format!("Unknown command: {}", command.command)),
         }
     }
 }
 
+/// Tells the daemon how a command went, so callers waiting on it don't have to
+/// guess from the pipe's exit code.
+fn report_command_result(id: &str, result: Result<(), String>) {
+    let body = CommandResult {
+        success: result.is_ok(),
+        error: result.err(),
+    };
+
+    let body = serde_json::to_vec(&body).unwrap();
+    let mut headers = BTreeMap::new();
+    headers.insert("Content-Type".to_string(), "application/json".to_string());
+    web_request(
+        format!("{}/zellij/commands/{}/result", DAEMON_URL, id),
+        HttpVerb::Post,
+        headers,
+        body,
+        BTreeMap::new(),
+    );
+}
+
 register_plugin!(State);
 
 impl ZellijPlugin for State {
-    fn load(&mut self, _configuration: BTreeMap<String, String>) {
+    fn load(&mut self, configuration: BTreeMap<String, String>) {
+        self.poll_commands = configuration.get("transport").map(String::as_str) == Some("poll");
+
+        let ids = get_plugin_ids();
+        self.instance_id = format!("{}-{}", ids.zellij_pid, ids.plugin_id);
+
         request_permission(&[
             PermissionType::RunCommands,
             PermissionType::ChangeApplicationState,
@@ -131,6 +435,7 @@ impl ZellijPlugin for State {
             EventType::FailedToChangeHostFolder,
             EventType::PaneClosed,
             EventType::PaneUpdate,
+            EventType::Timer,
         ]);
     }
 
@@ -146,29 +451,46 @@ impl ZellijPlugin for State {
                 let host_path = PathBuf::from(&"/var/log");
                 change_host_folder(host_path);
 
+                if self.poll_commands {
+                    self.poll_for_commands();
+                }
+                if !self.heartbeat_started {
+                    self.heartbeat_started = true;
+                    self.heartbeat();
+                }
+
                 should_render = false;
             }
+            Event::Timer(_elapsed) => {
+                self.heartbeat();
+            }
             Event::HostFolderChanged(_host_folder) => {
                 Logger::get().start_tracing();
             }
             Event::SessionUpdate(sessions, _) => {
+                // Zellij only tells us the cwd of the session we're loaded in,
+                // the daemon infers workspaces for the rest once they report.
+                let current_cwd = get_plugin_ids().initial_cwd.display().to_string();
+                self.host_session = sessions
+                    .iter()
+                    .find(|session| session.is_current_session)
+                    .map(|session| session.name.clone());
+
                 let session_updates: Vec<SessionUpdate> = sessions
                     .iter()
-                    .map(|session| SessionUpdate {
-                        name: session.name.clone(),
-                        is_current_session: session.is_current_session,
-                    })
+                    .map(|session| SessionUpdate::from_session_info(session, &current_cwd))
                     .collect();
 
                 let req = SessionUpdateRequest {
-                    id: String::from("test string"),
+                    id: self.instance_id.clone(),
+                    version: PLUGIN_VERSION.to_string(),
                     sessions: session_updates,
                 };
 
                 let body = serde_json::to_vec(&req).unwrap();
                 let context = BTreeMap::new();
                 web_request(
-                    String::from("http://localhost:3333/zellij/sessions"),
+                    format!("{}/zellij/sessions", DAEMON_URL),
                     HttpVerb::Put,
                     BTreeMap::new(),
                     body,
@@ -182,6 +504,16 @@ impl ZellijPlugin for State {
                     should_render = false;
                 }
             },
+            Event::WebRequestResult(status, _headers, raw_body, context)
+                if context.get("request").map(String::as_str) == Some("poll") =>
+            {
+                self.handle_poll_result(status, raw_body);
+            }
+            Event::WebRequestResult(status, _headers, raw_body, context)
+                if context.get("request").map(String::as_str) == Some("register") =>
+            {
+                self.handle_register_result(status, raw_body);
+            }
             Event::WebRequestResult(status, _headers, raw_body, _context) => unsafe {
                 if status != 200 {
                     let body = String::from_utf8_unchecked(raw_body);